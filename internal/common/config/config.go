@@ -10,18 +10,20 @@ import (
 
 // kafkaOptions implementa a interface IKafkaOptions
 type kafkaOptions struct {
-	Brokers          string
-	GroupId          string
-	Offset           enums.AutoOffsetReset
-	UserName         string
-	Password         string
-	SecurityProtocol enums.SecurityProtocol
-	SaslMechanisms   enums.SaslMechanisms
-	SchemaRegistry   ISchemaRegistryOptions
-	RequestTimeout   int
-	ProducerPriority enums.ProducerOrderPriority
-	ConsumerPriority enums.ConsumerOrderPriority
-	build            bool
+	Brokers            string
+	GroupId            string
+	Offset             enums.AutoOffsetReset
+	UserName           string
+	Password           string
+	SecurityProtocol   enums.SecurityProtocol
+	SaslMechanisms     enums.SaslMechanisms
+	SchemaRegistry     ISchemaRegistryOptions
+	RequestTimeout     int
+	ProducerPriority   enums.ProducerOrderPriority
+	ConsumerPriority   enums.ConsumerOrderPriority
+	TransactionalId    string
+	AssignmentStrategy string
+	build              bool
 }
 
 // SchemaRegistryOptions implementa a interface ISchemaRegistryOptions
@@ -100,6 +102,14 @@ func (k *kafkaOptions) SetConsumerPriority(consumerPriority enums.ConsumerOrderP
 	k.ConsumerPriority = consumerPriority
 }
 
+func (k *kafkaOptions) SetTransactionalId(transactionalId string) {
+	k.TransactionalId = transactionalId
+}
+
+func (k *kafkaOptions) SetAssignmentStrategy(assignmentStrategy string) {
+	k.AssignmentStrategy = assignmentStrategy
+}
+
 // ==========================================================================
 // Métodos KafkaOptions (Getters e validação)
 // ==========================================================================
@@ -193,6 +203,14 @@ func (k *kafkaOptions) GetConsumerPriority() string {
 	return string(k.ConsumerPriority)
 }
 
+func (k *kafkaOptions) GetTransactionalId() string {
+	return k.TransactionalId
+}
+
+func (k *kafkaOptions) GetAssignmentStrategy() string {
+	return k.AssignmentStrategy
+}
+
 func (k *kafkaOptions) GetSchemaRegistry() ISchemaRegistryOptions {
 	return k.SchemaRegistry
 }
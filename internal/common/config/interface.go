@@ -38,6 +38,12 @@ type IKafkaOptions interface {
 	// GetConsumerPriority retorna a prioridade configurada para o consumidor
 	GetConsumerPriority() string
 
+	// GetTransactionalId retorna o transactional.id do produtor transacional (vazio = desabilitado)
+	GetTransactionalId() string
+
+	// GetAssignmentStrategy retorna o partition.assignment.strategy dos consumidores (vazio = padrão do librdkafka)
+	GetAssignmentStrategy() string
+
 	// GetSchemaRegistry retorna as configurações do Schema Registry
 	GetSchemaRegistry() ISchemaRegistryOptions
 
@@ -0,0 +1,24 @@
+package enums
+
+// ==========================================================================
+// Modos de Commit
+// ==========================================================================
+
+// CommitMode define como o consumidor confirma os offsets das mensagens processadas
+// Em todos os modos apenas offsets de mensagens já tratadas são confirmados
+type CommitMode string
+
+const (
+	// COMMIT_MODE_PER_MESSAGE confirma de forma síncrona o offset de cada mensagem tratada
+	// Menor reprocessamento após falhas, ao custo de uma requisição ao broker por mensagem
+	COMMIT_MODE_PER_MESSAGE CommitMode = "PER_MESSAGE"
+
+	// COMMIT_MODE_PERIODIC armazena os offsets tratados com StoreOffsets e o librdkafka os confirma
+	// de forma assíncrona a cada auto.commit.interval.ms
+	// Após uma falha podem ser reprocessadas as mensagens do último intervalo
+	COMMIT_MODE_PERIODIC CommitMode = "PERIODIC"
+
+	// COMMIT_MODE_MANUAL confirma apenas os offsets informados pela aplicação
+	// Útil quando o processamento termina fora do handler
+	COMMIT_MODE_MANUAL CommitMode = "MANUAL"
+)
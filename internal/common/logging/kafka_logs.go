@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Funções
+// ==========================================================================
+
+// ForwardClientLogs encaminha os logs do librdkafka de um cliente para o logger informado.
+// Os níveis syslog do librdkafka são convertidos: 0-3 erro, 4 aviso, 5-6 informação e 7 debug.
+// A goroutine termina quando o cliente é fechado.
+func ForwardClientLogs(log logger.ILogger, client string, logs chan kafka.LogEvent) {
+	if logs == nil {
+		return
+	}
+
+	go func() {
+		for event := range logs {
+			args := []any{"client", client, "name", event.Name, "tag", event.Tag}
+			switch {
+			case event.Level <= 3:
+				log.Error(event.Message, args...)
+			case event.Level == 4:
+				log.Warn(event.Message, args...)
+			case event.Level <= 6:
+				log.Info(event.Message, args...)
+			default:
+				log.Debug(event.Message, args...)
+			}
+		}
+	}()
+}
@@ -2,6 +2,8 @@ package setup
 
 import (
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/Dieg657/kafka-toolkit-lib/internal/common/config"
 	"github.com/Dieg657/kafka-toolkit-lib/internal/common/enums"
@@ -13,9 +15,10 @@ import (
 // Tipos e Propriedades
 // ==========================================================================
 
-// kafkaConsumerSetup implementação concreta privada
+// kafkaConsumerSetup implementação concreta privada.
+// Funciona como fábrica: cada assinatura cria o seu próprio consumidor Kafka.
 type kafkaConsumerSetup struct {
-	consumerKafka *kafka.Consumer
+	options config.IKafkaOptions
 }
 
 // ==========================================================================
@@ -32,6 +35,9 @@ func NewKafkaConsumerSetup(options config.IKafkaOptions) (setup.IKafkaConsumerSe
 	return consumer, nil
 }
 
+// defaultMaxPollInterval é o max.poll.interval.ms padrão do librdkafka, em milissegundos
+const defaultMaxPollInterval = 300000
+
 // ==========================================================================
 // Configurações de Prioridade do Consumidor
 // ==========================================================================
@@ -110,45 +116,111 @@ var consumerPriorityConfigs = map[enums.ConsumerOrderPriority]func(*kafka.Config
 // Métodos Públicos
 // ==========================================================================
 
-// GetKafkaConsumer retorna a instância do consumidor Kafka
-func (cs *kafkaConsumerSetup) GetKafkaConsumer() *kafka.Consumer {
-	return cs.consumerKafka
-}
-
-// ==========================================================================
-// Métodos Privados
-// ==========================================================================
-
-// New inicializa um novo consumidor Kafka com as configurações especificadas
-func (cs *kafkaConsumerSetup) New(options config.IKafkaOptions) error {
+// NewKafkaConsumer cria um novo consumidor Kafka com as configurações do setup.
+// Cada chamada retorna uma instância independente, com sua própria participação no grupo.
+// As configurações informadas em overrides substituem as do ambiente.
+func (cs *kafkaConsumerSetup) NewKafkaConsumer(overrides setup.ConsumerOverrides) (*kafka.Consumer, error) {
 	// Obter nome do host para identificação do cliente
 	hostname, err := os.Hostname()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	options := cs.options
+
+	groupId := options.GetGroupId()
+	if overrides.GroupId != "" {
+		groupId = overrides.GroupId
+	}
+	groupId += overrides.GroupSuffix
+
+	priority := cs.priority(overrides)
+
 	// Configuração base do consumidor
 	configMap := &kafka.ConfigMap{
 		"bootstrap.servers": options.GetBrokers(),
-		"group.id":          options.GetGroupId(),
+		"group.id":          groupId,
 		"client.id":         hostname,
 		"security.protocol": options.GetSecurityProtocol(),
 		"sasl.mechanism":    options.GetSaslMechanisms(),
 		"sasl.username":     options.GetUserName(),
 		"sasl.password":     options.GetPassword(),
 		"auto.offset.reset": options.GetOffset(),
+
+		// Logs do librdkafka são entregues em Logs() e encaminhados ao logger da biblioteca
+		"go.logs.channel.enable": true,
 	}
 
 	// Aplicar configurações específicas da prioridade escolhida
-	setConsumerOrderPriority(enums.ConsumerOrderPriority(options.GetConsumerPriority()), configMap)
+	setConsumerOrderPriority(priority, configMap)
+
+	// O auto.offset.reset da assinatura prevalece sobre o da prioridade
+	if overrides.AutoOffsetReset != "" {
+		configMap.SetKey("auto.offset.reset", string(overrides.AutoOffsetReset))
+	}
+
+	// O consumo transacional não pode ler mensagens de transações abortadas ou ainda abertas
+	if overrides.ReadCommitted {
+		configMap.SetKey("isolation.level", "read_committed")
+	}
+
+	// Estratégia de atribuição de partições, por exemplo "cooperative-sticky" para o protocolo cooperativo
+	assignmentStrategy := options.GetAssignmentStrategy()
+	if overrides.AssignmentStrategy != "" {
+		assignmentStrategy = overrides.AssignmentStrategy
+	}
+	if assignmentStrategy != "" {
+		configMap.SetKey("partition.assignment.strategy", assignmentStrategy)
+	}
+
+	// Sinaliza o fim de cada partição, usado pelo consumo limitado
+	if overrides.PartitionEOF {
+		configMap.SetKey("enable.partition.eof", "true")
+	}
+
+	// Apenas offsets de mensagens tratadas são confirmados: no modo periódico o librdkafka confirma
+	// os offsets armazenados com StoreOffsets, nos demais a biblioteca os confirma explicitamente
+	if overrides.CommitMode != "" {
+		configMap.SetKey("enable.auto.offset.store", "false")
+		configMap.SetKey("enable.auto.commit", strconv.FormatBool(overrides.CommitMode == enums.COMMIT_MODE_PERIODIC))
+		if overrides.CommitInterval > 0 {
+			configMap.SetKey("auto.commit.interval.ms", int(overrides.CommitInterval.Milliseconds()))
+		}
+	}
 
 	// Criar o consumidor Kafka
-	consumer, err := kafka.NewConsumer(configMap)
-	if err != nil {
-		return err
+	return kafka.NewConsumer(configMap)
+}
+
+// MaxPollInterval retorna o max.poll.interval.ms definido pela prioridade de um consumidor criado com
+// as configurações informadas, ou o padrão do librdkafka quando a prioridade não o define.
+func (cs *kafkaConsumerSetup) MaxPollInterval(overrides setup.ConsumerOverrides) time.Duration {
+	configMap := &kafka.ConfigMap{}
+	setConsumerOrderPriority(cs.priority(overrides), configMap)
+
+	value, err := configMap.Get("max.poll.interval.ms", defaultMaxPollInterval)
+	interval, ok := value.(int)
+	if err != nil || !ok {
+		interval = defaultMaxPollInterval
 	}
+	return time.Duration(interval) * time.Millisecond
+}
+
+// ==========================================================================
+// Métodos Privados
+// ==========================================================================
 
-	cs.consumerKafka = consumer
+// priority retorna a prioridade da assinatura ou, se ausente, a de KAFKA_CONSUMER_PRIORITY
+func (cs *kafkaConsumerSetup) priority(overrides setup.ConsumerOverrides) enums.ConsumerOrderPriority {
+	if overrides.Priority != "" {
+		return overrides.Priority
+	}
+	return enums.ConsumerOrderPriority(cs.options.GetConsumerPriority())
+}
+
+// New guarda as configurações usadas na criação dos consumidores
+func (cs *kafkaConsumerSetup) New(options config.IKafkaOptions) error {
+	cs.options = options
 	return nil
 }
 
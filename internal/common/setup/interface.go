@@ -1,26 +1,63 @@
 package setup
 
 import (
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/internal/common/enums"
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"github.com/confluentinc/confluent-kafka-go/v2/schemaregistry/serde/avro"
 	"github.com/confluentinc/confluent-kafka-go/v2/schemaregistry/serde/jsonschema"
 	"github.com/confluentinc/confluent-kafka-go/v2/schemaregistry/serde/protobuf"
 )
 
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// ConsumerOverrides reúne as configurações de uma assinatura que substituem as do ambiente.
+// Campos vazios mantêm os valores das variáveis de ambiente.
+type ConsumerOverrides struct {
+	// GroupId substitui KAFKA_GROUPID
+	GroupId string
+	// GroupSuffix é acrescentado ao grupo resolvido, formando um grupo próprio derivado do grupo da assinatura
+	GroupSuffix string
+	// AutoOffsetReset substitui o auto.offset.reset da prioridade e de KAFKA_AUTO_OFFSET_RESET
+	AutoOffsetReset enums.AutoOffsetReset
+	// Priority substitui KAFKA_CONSUMER_PRIORITY
+	Priority enums.ConsumerOrderPriority
+	// AssignmentStrategy substitui KAFKA_PARTITION_ASSIGNMENT_STRATEGY
+	AssignmentStrategy string
+	// PartitionEOF habilita o evento de fim de partição (enable.partition.eof)
+	PartitionEOF bool
+	// ReadCommitted força isolation.level=read_committed, qualquer que seja a prioridade
+	ReadCommitted bool
+	// CommitMode substitui o enable.auto.commit da prioridade. Em qualquer modo o armazenamento automático
+	// de offsets é desabilitado: apenas offsets armazenados ou confirmados pela biblioteca são confirmados.
+	CommitMode enums.CommitMode
+	// CommitInterval substitui o auto.commit.interval.ms do modo periódico
+	CommitInterval time.Duration
+}
+
 // ==========================================================================
 // Interfaces
 // ==========================================================================
 
-// IKafkaConsumerSetup define a interface para configuração do consumidor Kafka
+// IKafkaConsumerSetup define a fábrica de consumidores Kafka configurados
 type IKafkaConsumerSetup interface {
-	// GetKafkaConsumer retorna a instância do consumidor Kafka configurado
-	GetKafkaConsumer() *kafka.Consumer
+	// NewKafkaConsumer cria um novo consumidor Kafka; cada assinatura usa a sua própria instância
+	NewKafkaConsumer(overrides ConsumerOverrides) (*kafka.Consumer, error)
+
+	// MaxPollInterval retorna o max.poll.interval.ms de um consumidor criado com as configurações informadas
+	MaxPollInterval(overrides ConsumerOverrides) time.Duration
 }
 
 // IKafkaProducerSetup define a interface para configuração do produtor Kafka
 type IKafkaProducerSetup interface {
 	// GetKafkaProducer retorna a instância do produtor Kafka configurado
 	GetKafkaProducer() *kafka.Producer
+
+	// DeliveryTimeout retorna o message.timeout.ms do produtor, prazo em que o librdkafka entrega o relatório de entrega
+	DeliveryTimeout() time.Duration
 }
 
 // ISchemaRegistrySetup define a interface para interação com o Schema Registry
@@ -2,6 +2,7 @@ package setup
 
 import (
 	"os"
+	"time"
 
 	"github.com/Dieg657/kafka-toolkit-lib/internal/common/config"
 	"github.com/Dieg657/kafka-toolkit-lib/internal/common/enums"
@@ -16,9 +17,13 @@ import (
 
 // kafkaProducerSetup implementação concreta privada
 type kafkaProducerSetup struct {
-	producerKafka *kafka.Producer
+	producerKafka   *kafka.Producer
+	deliveryTimeout time.Duration
 }
 
+// defaultMessageTimeout é o message.timeout.ms padrão do librdkafka, em milissegundos
+const defaultMessageTimeout = 300000
+
 // NewKafkaProducerSetup cria uma nova instância da interface IKafkaProducerSetup
 func NewKafkaProducerSetup(options config.IKafkaOptions) (setup.IKafkaProducerSetup, error) {
 	producer := &kafkaProducerSetup{}
@@ -94,17 +99,7 @@ func (producerSetup *kafkaProducerSetup) New(options config.IKafkaOptions) error
 		return err
 	}
 
-	configMap := &kafka.ConfigMap{
-		"bootstrap.servers":  options.GetBrokers(),
-		"client.id":          hostname,
-		"request.timeout.ms": options.GetRequestTimeout(),
-		"security.protocol":  options.GetSecurityProtocol(),
-		"sasl.mechanism":     options.GetSaslMechanisms(),
-		"sasl.username":      options.GetUserName(),
-		"sasl.password":      options.GetPassword(),
-	}
-
-	setProducerOrderPriority(enums.ProducerOrderPriority(options.GetProducerPriority()), configMap)
+	configMap := newProducerConfigMap(options, hostname)
 
 	producer, err := kafka.NewProducer(configMap)
 
@@ -113,6 +108,7 @@ func (producerSetup *kafkaProducerSetup) New(options config.IKafkaOptions) error
 	}
 
 	producerSetup.producerKafka = producer
+	producerSetup.deliveryTimeout = messageTimeout(configMap)
 	return nil
 }
 
@@ -124,10 +120,47 @@ func (producerSetup *kafkaProducerSetup) GetKafkaProducer() *kafka.Producer {
 	return producerSetup.producerKafka
 }
 
+// DeliveryTimeout retorna o message.timeout.ms configurado no produtor
+func (producerSetup *kafkaProducerSetup) DeliveryTimeout() time.Duration {
+	return producerSetup.deliveryTimeout
+}
+
 // ==========================================================================
 // Métodos Privados
 // ==========================================================================
 
+// newProducerConfigMap monta a configuração base do produtor: conexão, segurança e prioridade
+func newProducerConfigMap(options config.IKafkaOptions, clientId string) *kafka.ConfigMap {
+	configMap := &kafka.ConfigMap{
+		"bootstrap.servers":  options.GetBrokers(),
+		"client.id":          clientId,
+		"request.timeout.ms": options.GetRequestTimeout(),
+		"security.protocol":  options.GetSecurityProtocol(),
+		"sasl.mechanism":     options.GetSaslMechanisms(),
+		"sasl.username":      options.GetUserName(),
+		"sasl.password":      options.GetPassword(),
+
+		// Logs do librdkafka são entregues em Logs() e encaminhados ao logger da biblioteca
+		"go.logs.channel.enable": true,
+
+		// Relatórios de entrega trazem os cabeçalhos para identificar o correlationId nos logs
+		"go.delivery.report.fields": "key,headers",
+	}
+
+	setProducerOrderPriority(enums.ProducerOrderPriority(options.GetProducerPriority()), configMap)
+	return configMap
+}
+
+// messageTimeout retorna o message.timeout.ms da configuração, ou o padrão do librdkafka quando ausente
+func messageTimeout(configMap *kafka.ConfigMap) time.Duration {
+	value, err := configMap.Get("message.timeout.ms", defaultMessageTimeout)
+	timeout, ok := value.(int)
+	if err != nil || !ok {
+		timeout = defaultMessageTimeout
+	}
+	return time.Duration(timeout) * time.Millisecond
+}
+
 // setProducerOrderPriority configura o produtor com base na prioridade escolhida
 func setProducerOrderPriority(priority enums.ProducerOrderPriority, configMap *kafka.ConfigMap) {
 	configFunc := producerPriorityConfigs[priority]
@@ -0,0 +1,43 @@
+package setup
+
+import (
+	"errors"
+	"os"
+
+	"github.com/Dieg657/kafka-toolkit-lib/internal/common/config"
+	"github.com/Dieg657/kafka-toolkit-lib/internal/common/setup"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// NewKafkaTransactionalProducerSetup cria o produtor transacional configurado com o transactional.id informado.
+// O produtor é separado do produtor padrão porque, com transactional.id, toda publicação precisa
+// ocorrer dentro de uma transação. A inicialização das transações (InitTransactions) é feita no primeiro uso.
+func NewKafkaTransactionalProducerSetup(options config.IKafkaOptions) (setup.IKafkaProducerSetup, error) {
+	if options.GetTransactionalId() == "" {
+		return nil, errors.New("transactional.id não configurado")
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	configMap := newProducerConfigMap(options, hostname)
+
+	// Transações exigem idempotência, confirmação de todas as réplicas e no máximo 5 requisições em paralelo
+	configMap.SetKey("transactional.id", options.GetTransactionalId())
+	configMap.SetKey("enable.idempotence", true)
+	configMap.SetKey("acks", "all")
+	configMap.SetKey("max.in.flight.requests.per.connection", 5)
+
+	producer, err := kafka.NewProducer(configMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafkaProducerSetup{producerKafka: producer, deliveryTimeout: messageTimeout(configMap)}, nil
+}
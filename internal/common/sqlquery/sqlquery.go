@@ -0,0 +1,51 @@
+package sqlquery
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// identifierPattern valida nomes de tabela, opcionalmente qualificados pelo schema
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// ==========================================================================
+// Funções Públicas
+// ==========================================================================
+
+// Rebind converte os marcadores "?" da consulta para o estilo de parâmetros do driver
+func Rebind(placeholder enums.SQLPlaceholder, query string) string {
+	if placeholder != enums.PlaceholderDollar {
+		return query
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(query) + 8)
+	position := 0
+	for _, char := range query {
+		if char != '?' {
+			builder.WriteRune(char)
+			continue
+		}
+		position++
+		builder.WriteByte('$')
+		builder.WriteString(strconv.Itoa(position))
+	}
+	return builder.String()
+}
+
+// ValidateTable verifica se o nome da tabela é um identificador SQL simples, pois ele é
+// concatenado às consultas e não pode ser passado como parâmetro
+func ValidateTable(table string) error {
+	if !identifierPattern.MatchString(table) {
+		return fmt.Errorf("nome de tabela inválido: %q", table)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// Valores padrão aplicados quando nenhum limite de lote é informado
+const (
+	defaultBatchMaxSize = 500
+	defaultBatchMaxWait = time.Second
+)
+
+// BatchOptions define os limites que disparam a entrega de um lote ao handler.
+// O lote é entregue assim que qualquer um dos limites for atingido.
+type BatchOptions struct {
+	// MaxSize é o número máximo de mensagens por lote
+	MaxSize int
+	// MaxBytes é a soma máxima do tamanho de chave e valor das mensagens do lote (0 = sem limite)
+	MaxBytes int
+	// MaxWait é o tempo máximo que a primeira mensagem do lote aguarda antes da entrega
+	MaxWait time.Duration
+}
+
+// BatchFailure representa a falha parcial de um lote.
+// O handler de lote retorna este erro para indicar quais mensagens falharam; as demais
+// são consideradas processadas.
+type BatchFailure struct {
+	// Failed contém os índices, no slice entregue ao handler, das mensagens que falharam
+	Failed []int
+	// Err é a causa da falha
+	Err error
+}
+
+// NewBatchFailure cria uma falha parcial de lote para os índices informados
+func NewBatchFailure(err error, failed ...int) *BatchFailure {
+	return &BatchFailure{Failed: failed, Err: err}
+}
+
+// Error implementa a interface error
+func (f *BatchFailure) Error() string {
+	return fmt.Sprintf("%d mensagem(ns) do lote falharam: %v", len(f.Failed), f.Err)
+}
+
+// Unwrap retorna a causa da falha
+func (f *BatchFailure) Unwrap() error {
+	return f.Err
+}
+
+// messageBatch acumula as mensagens Kafka de um lote em formação
+type messageBatch struct {
+	records  []*kafka.Message
+	keys     []string // Chaves de deduplicação das mensagens do lote, vazias quando não identificadas
+	skipped  []*kafka.Message
+	bytes    int
+	deadline time.Time
+}
+
+// ==========================================================================
+// Métodos
+// ==========================================================================
+
+// withDefaults retorna as opções com os valores padrão aplicados
+func (o BatchOptions) withDefaults() BatchOptions {
+	if o.MaxSize <= 0 {
+		o.MaxSize = defaultBatchMaxSize
+	}
+	if o.MaxWait <= 0 {
+		o.MaxWait = defaultBatchMaxWait
+	}
+	return o
+}
+
+// add inclui uma mensagem e a sua chave de deduplicação no lote, iniciando o prazo de espera na primeira mensagem
+func (b *messageBatch) add(msg *kafka.Message, key string, maxWait time.Duration) {
+	if len(b.records) == 0 {
+		b.deadline = time.Now().Add(maxWait)
+	}
+	b.records = append(b.records, msg)
+	b.keys = append(b.keys, key)
+	b.bytes += len(msg.Key) + len(msg.Value)
+}
+
+// skip registra uma mensagem descartada sem passar pelo handler, confirmada junto com o lote
+func (b *messageBatch) skip(msg *kafka.Message) {
+	b.skipped = append(b.skipped, msg)
+}
+
+// full indica se o lote atingiu o limite de tamanho ou de bytes
+func (b *messageBatch) full(options BatchOptions) bool {
+	if len(b.records) >= options.MaxSize {
+		return true
+	}
+	return options.MaxBytes > 0 && b.bytes >= options.MaxBytes
+}
+
+// expired indica se o prazo de espera do lote foi atingido
+func (b *messageBatch) expired() bool {
+	return len(b.records) > 0 && !time.Now().Before(b.deadline)
+}
+
+// reset descarta as mensagens acumuladas
+func (b *messageBatch) reset() {
+	b.records = nil
+	b.keys = nil
+	b.skipped = nil
+	b.bytes = 0
+}
+
+// commitPositions calcula, por partição, o próximo offset a confirmar após o lote.
+// Partições com mensagens falhas só avançam até a primeira falha; para essas partições
+// também é retornado o offset a partir do qual o consumo deve ser reposicionado.
+func (b *messageBatch) commitPositions(failed map[int]struct{}) (commits []kafka.TopicPartition, rewinds []kafka.TopicPartition) {
+	type position struct {
+		tp         kafka.TopicPartition
+		next       kafka.Offset
+		firstError kafka.Offset
+	}
+
+	positions := make(map[partitionKey]*position)
+	var order []partitionKey
+
+	// As mensagens descartadas são tratadas como processadas, após as mensagens do lote
+	all := append(append([]*kafka.Message{}, b.records...), b.skipped...)
+
+	for index, record := range all {
+		key := partitionKey{topic: *record.TopicPartition.Topic, partition: record.TopicPartition.Partition}
+		pos, exists := positions[key]
+		if !exists {
+			pos = &position{tp: record.TopicPartition, next: kafka.OffsetInvalid, firstError: kafka.OffsetInvalid}
+			positions[key] = pos
+			order = append(order, key)
+		}
+
+		if _, isFailed := failed[index]; isFailed {
+			if pos.firstError == kafka.OffsetInvalid || record.TopicPartition.Offset < pos.firstError {
+				pos.firstError = record.TopicPartition.Offset
+			}
+			continue
+		}
+
+		if record.TopicPartition.Offset+1 > pos.next {
+			pos.next = record.TopicPartition.Offset + 1
+		}
+	}
+
+	for _, key := range order {
+		pos := positions[key]
+		tp := pos.tp
+
+		if pos.firstError != kafka.OffsetInvalid {
+			tp.Offset = pos.firstError
+			commits = append(commits, tp)
+			rewinds = append(rewinds, tp)
+			continue
+		}
+
+		tp.Offset = pos.next
+		commits = append(commits, tp)
+	}
+
+	return commits, rewinds
+}
@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Teste do cálculo de commit de um lote com falha parcial
+// A partição com falha só avança até a primeira mensagem falha e é reposicionada nela;
+// as demais partições confirmam o lote inteiro.
+func TestMessageBatchCommitPositionsWithPartialFailure(t *testing.T) {
+	topic := "analytics"
+	record := func(partition int32, offset int64) *kafka.Message {
+		return &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: partition, Offset: kafka.Offset(offset)}}
+	}
+
+	batch := &messageBatch{}
+	batch.add(record(0, 100), "", time.Second)
+	batch.add(record(1, 50), "", time.Second)
+	batch.add(record(0, 101), "", time.Second)
+	batch.add(record(0, 102), "", time.Second)
+	batch.add(record(1, 51), "", time.Second)
+
+	// Falha no índice 2 (partição 0, offset 101)
+	commits, rewinds := batch.commitPositions(map[int]struct{}{2: {}})
+
+	assert.Len(t, commits, 2)
+	assert.Equal(t, int32(0), commits[0].Partition)
+	assert.Equal(t, kafka.Offset(101), commits[0].Offset, "Partição com falha não pode avançar além da primeira falha!")
+	assert.Equal(t, int32(1), commits[1].Partition)
+	assert.Equal(t, kafka.Offset(52), commits[1].Offset)
+
+	assert.Len(t, rewinds, 1)
+	assert.Equal(t, int32(0), rewinds[0].Partition)
+	assert.Equal(t, kafka.Offset(101), rewinds[0].Offset)
+}
+
+// Teste dos limites de tamanho e bytes do lote
+func TestMessageBatchLimits(t *testing.T) {
+	topic := "analytics"
+	options := BatchOptions{MaxSize: 3, MaxBytes: 10}.withDefaults()
+
+	batch := &messageBatch{}
+	batch.add(&kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic}, Value: []byte("1234")}, "", options.MaxWait)
+	assert.False(t, batch.full(options))
+
+	batch.add(&kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic}, Value: []byte("123456")}, "", options.MaxWait)
+	assert.True(t, batch.full(options), "Lote deveria estar cheio pelo limite de bytes")
+
+	batch.reset()
+	assert.False(t, batch.expired())
+}
+
+// Teste da deduplicação no consumo em lotes
+// A mensagem cuja chave já foi tratada em um lote anterior é retirada dos lotes seguintes.
+func TestConsumeBatchDeduplicates(t *testing.T) {
+	cluster, err := kafka.NewMockCluster(1)
+	require.NoError(t, err)
+	defer cluster.Close()
+	require.NoError(t, cluster.CreateTopic("orders", 1, 1))
+
+	producer, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": cluster.BootstrapServers()})
+	require.NoError(t, err)
+	defer producer.Close()
+
+	topic := "orders"
+	keys := []string{"A", "B", "A", "C"}
+	deliveries := make(chan kafka.Event, len(keys))
+	for i, key := range keys {
+		require.NoError(t, producer.Produce(&kafka.Message{
+			TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 0},
+			Key:            []byte(key),
+			Value:          []byte(fmt.Sprintf("%q", fmt.Sprint(i))),
+		}, deliveries))
+	}
+	for range keys {
+		require.NoError(t, (<-deliveries).(*kafka.Message).TopicPartition.Error)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &kafkaConsumer[string]{ctx: ctx, logger: logger.NewNopLogger(), consumerSetup: mockConsumerSetup{servers: cluster.BootstrapServers()}}
+	c.initializeDeserializers()
+
+	var mu sync.Mutex
+	var handled []string
+	done := make(chan error, 1)
+	go func() {
+		done <- c.ConsumeBatch("orders", enums.JsonDeserialization, enums.OnDeserializationFailedStopHost,
+			BatchOptions{MaxSize: 1},
+			ConsumeOptions{
+				GroupId:       "orders-group",
+				Partitions:    []int32{0},
+				Deduplication: DeduplicationOptions{Store: NewMemoryDeduplicationStore(0, 0), Key: DeduplicateByKey()},
+			},
+			func(messages []message.Message[string]) error {
+				mu.Lock()
+				defer mu.Unlock()
+				for _, msg := range messages {
+					handled = append(handled, msg.Data)
+				}
+				return nil
+			})
+	}()
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(handled) > 0 && handled[len(handled)-1] == "3"
+	}, 10*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"0", "1", "3"}, handled, "A mensagem repetida da chave A não deveria ser entregue")
+}
@@ -0,0 +1,169 @@
+package engine
+
+import (
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// endOffsets controla o consumo limitado de uma assinatura. Na primeira atribuição de cada partição
+// é registrado o seu offset final, a marca d'água superior naquele momento; a partição termina quando
+// a mensagem anterior a esse offset é processada ou quando o librdkafka sinaliza o fim da partição.
+// Executa apenas na goroutine de consumo.
+type endOffsets struct {
+	ends     map[partitionKey]kafka.Offset
+	reached  map[partitionKey]bool
+	assigned map[partitionKey]kafka.TopicPartition
+	logger   logger.ILogger
+}
+
+// watermarkTimeout é o tempo máximo, em milissegundos, da consulta das marcas d'água de uma partição
+const watermarkTimeout = 10000
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// newEndOffsets cria o controle do consumo limitado. Retorna nil quando o modo não foi habilitado.
+func newEndOffsets(bounded bool, log logger.ILogger) *endOffsets {
+	if !bounded {
+		return nil
+	}
+
+	return &endOffsets{
+		ends:     make(map[partitionKey]kafka.Offset),
+		reached:  make(map[partitionKey]bool),
+		assigned: make(map[partitionKey]kafka.TopicPartition),
+		logger:   log,
+	}
+}
+
+// ==========================================================================
+// Métodos
+// ==========================================================================
+
+// assign registra as partições atribuídas e consulta o offset final das que ainda não o têm.
+// Uma partição atribuída novamente mantém o offset final da primeira atribuição.
+func (e *endOffsets) assign(client *kafka.Consumer, partitions []kafka.TopicPartition) {
+	if e == nil {
+		return
+	}
+
+	for _, tp := range partitions {
+		e.assigned[keyOf(tp)] = tp
+	}
+	e.resolve(client)
+}
+
+// revoke deixa de acompanhar as partições revogadas
+func (e *endOffsets) revoke(partitions []kafka.TopicPartition) {
+	if e == nil {
+		return
+	}
+
+	for _, tp := range partitions {
+		delete(e.assigned, keyOf(tp))
+	}
+}
+
+// beyond indica se a mensagem está além do offset final da sua partição.
+// A mensagem não deve ser processada e a partição é encerrada.
+func (e *endOffsets) beyond(client *kafka.Consumer, record *kafka.Message) bool {
+	if e == nil {
+		return false
+	}
+
+	end, exists := e.ends[keyOf(record.TopicPartition)]
+	if !exists || record.TopicPartition.Offset < end {
+		return false
+	}
+
+	e.reach(client, record.TopicPartition)
+	return true
+}
+
+// advance registra a mensagem processada, encerrando a partição quando ela alcança o offset final
+func (e *endOffsets) advance(client *kafka.Consumer, tp kafka.TopicPartition) {
+	if e == nil {
+		return
+	}
+
+	end, exists := e.ends[keyOf(tp)]
+	if exists && tp.Offset+1 >= end {
+		e.reach(client, tp)
+	}
+}
+
+// endOfPartition trata o evento de fim de partição do librdkafka (enable.partition.eof)
+func (e *endOffsets) endOfPartition(client *kafka.Consumer, eof kafka.PartitionEOF) {
+	if e == nil {
+		return
+	}
+
+	tp := kafka.TopicPartition(eof)
+	end, exists := e.ends[keyOf(tp)]
+	if exists && tp.Offset >= end {
+		e.reach(client, tp)
+	}
+}
+
+// finished indica se todas as partições atribuídas alcançaram o offset final.
+// Sem partições atribuídas o consumo ainda não começou e não está concluído.
+func (e *endOffsets) finished(client *kafka.Consumer) bool {
+	if e == nil || len(e.assigned) == 0 {
+		return false
+	}
+
+	e.resolve(client)
+	for key := range e.assigned {
+		if !e.reached[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// isReached indica se a partição já alcançou o offset final e deve permanecer pausada
+func (e *endOffsets) isReached(key partitionKey) bool {
+	return e != nil && e.reached[key]
+}
+
+// resolve consulta o offset final das partições atribuídas que ainda não o têm.
+// Partições vazias terminam imediatamente; em caso de erro a consulta é repetida na próxima verificação.
+func (e *endOffsets) resolve(client *kafka.Consumer) {
+	for key, tp := range e.assigned {
+		if _, exists := e.ends[key]; exists {
+			continue
+		}
+
+		low, high, err := client.QueryWatermarkOffsets(key.topic, key.partition, watermarkTimeout)
+		if err != nil {
+			e.logger.Error("Error on query watermark offsets", partitionFields(tp, logger.FieldError, err)...)
+			continue
+		}
+
+		e.ends[key] = kafka.Offset(high)
+		e.logger.Info("Partition end offset set", partitionFields(tp, "endOffset", high)...)
+		if high <= low {
+			e.reach(client, tp)
+		}
+	}
+}
+
+// reach encerra a partição e a pausa para que mensagens posteriores ao offset final não sejam buscadas
+func (e *endOffsets) reach(client *kafka.Consumer, tp kafka.TopicPartition) {
+	key := keyOf(tp)
+	if e.reached[key] {
+		return
+	}
+	e.reached[key] = true
+
+	paused := kafka.TopicPartition{Topic: tp.Topic, Partition: tp.Partition}
+	if err := client.Pause([]kafka.TopicPartition{paused}); err != nil {
+		e.logger.Error("Error on pause finished partition", partitionFields(paused, logger.FieldError, err)...)
+	}
+	e.logger.Info("Partition end offset reached", partitionFields(tp)...)
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Teste da conclusão do consumo limitado
+// O consumo só termina quando todas as partições atribuídas alcançaram o offset final.
+func TestEndOffsetsFinishedWhenAllPartitionsReached(t *testing.T) {
+	assert.Nil(t, newEndOffsets(false, logger.NewNopLogger()), "Sem consumo limitado não deveria haver controle")
+
+	var disabled *endOffsets
+	assert.False(t, disabled.finished(nil))
+
+	ends := newEndOffsets(true, logger.NewNopLogger())
+	assert.False(t, ends.finished(nil), "Sem partições atribuídas o consumo não deveria estar concluído")
+
+	topic := "orders"
+	first := partitionKey{topic: topic, partition: 0}
+	second := partitionKey{topic: topic, partition: 1}
+	ends.assigned[first] = kafka.TopicPartition{Topic: &topic, Partition: 0}
+	ends.assigned[second] = kafka.TopicPartition{Topic: &topic, Partition: 1}
+	ends.ends[first] = 10
+	ends.ends[second] = 5
+
+	ends.advance(nil, kafka.TopicPartition{Topic: &topic, Partition: 0, Offset: 3})
+	assert.False(t, ends.isReached(first), "Mensagens anteriores ao offset final não deveriam encerrar a partição")
+
+	ends.reached[first] = true
+	assert.False(t, ends.finished(nil))
+
+	ends.revoke([]kafka.TopicPartition{{Topic: &topic, Partition: 1}})
+	require.Len(t, ends.assigned, 1)
+	assert.True(t, ends.finished(nil), "Partições revogadas não deveriam impedir a conclusão")
+}
+
+// Teste das partições atribuídas manualmente de cada tópico
+func TestSubscriptionManualPartitions(t *testing.T) {
+	sub, err := newSubscription("orders", ConsumeOptions{Topics: []string{"payments"}, Partitions: []int32{0, 2}}, logger.NewNopLogger())
+	require.NoError(t, err)
+	require.True(t, sub.manual())
+
+	partitions := sub.partitions()
+	require.Len(t, partitions, 4)
+	assert.Equal(t, "orders[0],orders[2],payments[0],payments[2]", partitionsText(partitions))
+	assert.Equal(t, kafka.OffsetStored, partitions[0].Offset, "Partições deveriam começar do offset confirmado")
+
+	_, err = newSubscription(`^orders\..*`, ConsumeOptions{Partitions: []int32{0}}, logger.NewNopLogger())
+	assert.Error(t, err, "Atribuição manual não deveria aceitar expressões regulares")
+}
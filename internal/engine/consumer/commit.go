@@ -0,0 +1,95 @@
+package engine
+
+import (
+	internalEnums "github.com/Dieg657/kafka-toolkit-lib/internal/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Métodos Privados
+// ==========================================================================
+
+// handled registra a mensagem tratada conforme o modo de commit da assinatura
+func (c *kafkaConsumer[TData]) handled(tp kafka.TopicPartition) {
+	next := tp
+	next.Offset++
+	c.advanceOffsets([]kafka.TopicPartition{next})
+}
+
+// advanceOffsets registra os próximos offsets das mensagens tratadas conforme o modo de commit:
+// confirma-os de forma síncrona (PER_MESSAGE) ou os armazena para o commit assíncrono do librdkafka
+// (PERIODIC). No commit manual apenas os offsets solicitados pela aplicação são confirmados.
+func (c *kafkaConsumer[TData]) advanceOffsets(offsets []kafka.TopicPartition) {
+	if c.skipCommit() || len(offsets) == 0 {
+		return
+	}
+
+	switch c.commitMode {
+	case internalEnums.COMMIT_MODE_PER_MESSAGE:
+		c.commitOffsets(c.client, offsets, "Error on commit offsets")
+	case internalEnums.COMMIT_MODE_PERIODIC:
+		if _, err := c.client.StoreOffsets(offsets); err != nil {
+			c.logger.Error("Error on store offsets", "partitions", partitionsText(offsets), logger.FieldError, err)
+		}
+	}
+}
+
+// commitRequested confirma de forma síncrona os offsets solicitados pela aplicação com Controller.Commit
+func (c *kafkaConsumer[TData]) commitRequested(sub *subscription) {
+	if c.commitMode != internalEnums.COMMIT_MODE_MANUAL {
+		return
+	}
+	c.commitOffsets(c.client, sub.options.Controller.takeCommits(nil), "Error on commit requested offsets")
+}
+
+// commitFinal confirma de forma síncrona os offsets tratados das partições informadas, usado na
+// revogação e, com partitions nil, no encerramento. No commit manual são confirmados os offsets ainda
+// pendentes solicitados pela aplicação; no periódico, também os armazenados e ainda não confirmados.
+func (c *kafkaConsumer[TData]) commitFinal(client *kafka.Consumer, sub *subscription, offsets []kafka.TopicPartition, partitions []kafka.TopicPartition) {
+	if c.skipCommit() {
+		return
+	}
+
+	switch c.commitMode {
+	case internalEnums.COMMIT_MODE_MANUAL:
+		c.commitOffsets(client, sub.options.Controller.takeCommits(partitions), "Error on final commit")
+	case internalEnums.COMMIT_MODE_PERIODIC:
+		if len(offsets) > 0 {
+			if _, err := client.StoreOffsets(offsets); err != nil {
+				c.logger.Error("Error on store offsets", "partitions", partitionsText(offsets), logger.FieldError, err)
+			}
+		}
+		if _, err := client.Commit(); err != nil && !isNoOffset(err) {
+			c.logger.Error("Error on final commit", logger.FieldError, err)
+		}
+	default:
+		c.commitOffsets(client, offsets, "Error on final commit")
+	}
+}
+
+// commitOffsets confirma os offsets de forma síncrona, ignorando a ausência de offsets a confirmar
+func (c *kafkaConsumer[TData]) commitOffsets(client *kafka.Consumer, offsets []kafka.TopicPartition, errorMessage string) {
+	if len(offsets) == 0 {
+		return
+	}
+
+	if _, err := client.CommitOffsets(offsets); err != nil && !isNoOffset(err) {
+		c.logger.Error(errorMessage, "partitions", partitionsText(offsets), logger.FieldError, err)
+	}
+}
+
+// skipCommit indica se os offsets não devem ser confirmados: sem grupo de consumidores não há onde confirmá-los
+func (c *kafkaConsumer[TData]) skipCommit() bool {
+	return c.standalone
+}
+
+// ==========================================================================
+// Funções
+// ==========================================================================
+
+// isNoOffset indica se o erro do commit é apenas a ausência de offsets a confirmar
+func isNoOffset(err error) bool {
+	kafkaErr, ok := err.(kafka.Error)
+	return ok && kafkaErr.Code() == kafka.ErrNoOffset
+}
@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/google/uuid"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// ContextHandler processa uma mensagem recebendo o contexto da execução, derivado do contexto do
+// consumidor, com o prazo da mensagem e os seus metadados (veja MessageInfoFromContext)
+type ContextHandler[TData any] func(ctx context.Context, message message.Message[TData]) error
+
+// MessageInfo reúne os metadados da mensagem em processamento disponíveis no contexto do handler
+type MessageInfo struct {
+	// Topic é o tópico de onde a mensagem foi consumida
+	Topic string
+	// Partition é a partição de onde a mensagem foi consumida
+	Partition int32
+	// Offset é a posição da mensagem na partição
+	Offset int64
+	// Key contém os bytes da chave do registro Kafka
+	Key []byte
+	// CorrelationId é o identificador de correlação da mensagem
+	CorrelationId uuid.UUID
+	// Headers contém os cabeçalhos do registro Kafka
+	Headers map[string][]byte
+	// Deadline é o instante limite da execução do handler
+	Deadline time.Time
+}
+
+// ErrHandlerAborted indica que o watchdog abandonou um handler que excedeu o prazo da mensagem
+var ErrHandlerAborted = errors.New("handler abandonado pelo watchdog após exceder o prazo da mensagem")
+
+// handlerDeadlineShare é o percentual do max.poll.interval.ms usado como prazo padrão e máximo do handler,
+// deixando margem para o watchdog agir antes de o consumidor ser removido do grupo
+const handlerDeadlineShare = 80
+
+// messageInfoKey é a chave dos metadados da mensagem no contexto do handler
+type messageInfoKey struct{}
+
+// execution é o resultado de um handler executado em segundo plano pelo watchdog
+type execution struct {
+	err      error
+	panic    any
+	panicked bool
+}
+
+// ==========================================================================
+// Métodos Públicos
+// ==========================================================================
+
+// ConsumeWithContext inicia o consumo sequencial de um tópico Kafka com um handler que recebe um contexto por mensagem.
+// O contexto é cancelado no encerramento do consumidor e expira no prazo da mensagem (HandlerTimeout). Um watchdog
+// registra os handlers que excedem o prazo e, com WatchdogAbort, abandona-os e trata a mensagem como falha, antes
+// que o max.poll.interval.ms seja excedido. O prazo é único por mensagem e compartilhado pelas retentativas da
+// RetryPolicy, que param quando o prazo restante não comporta outra tentativa. Os tópicos de retentativa e a
+// dead-letter seguem Consume, exceto que a mensagem abandonada não é repetida pela RetryPolicy, pois o handler
+// abandonado ainda pode estar em execução: ela segue direto para o tópico de retentativa ou para a dead-letter.
+//
+// Parâmetros:
+//   - topic: Nome do tópico Kafka para consumo
+//   - deserialization: Formato de deserialização a ser usado
+//   - strategy: Estratégia de tratamento de erro de deserialização
+//   - options: Configurações opcionais da assinatura (prazo, watchdog, retentativas, dead-letter)
+//   - handler: Função chamada para cada mensagem com o contexto da execução
+//
+// Retorno:
+//   - error: Erro caso ocorra falha no consumo, incluindo *DeserializationError quando a estratégia encerra o consumo
+func (c *kafkaConsumer[TData]) ConsumeWithContext(topic string, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, options ConsumeOptions, handler ContextHandler[TData]) error {
+	return c.consume(topic, deserialization, strategy, options, func(c *kafkaConsumer[TData]) messageProcessor[TData] {
+		attempt := c.contextual(options, handler)
+		return c.sequential(func(sub *subscription, record *kafka.Message, baseMessage message.Message[TData]) error {
+			// O prazo é calculado na execução, após a assinatura resolver o max.poll.interval.ms
+			return c.handleWithin(sub, record, baseMessage, c.handlerTimeout(options.HandlerTimeout), attempt)
+		})
+	})
+}
+
+// ==========================================================================
+// Métodos Privados
+// ==========================================================================
+
+// contextual adapta o handler com contexto: cada tentativa recebe um contexto derivado do contexto do
+// consumidor, com o prazo da mensagem e os seus metadados, e é acompanhada pelo watchdog.
+// Sem prazo informado, a tentativa usa o prazo das opções a partir do seu início.
+func (c *kafkaConsumer[TData]) contextual(options ConsumeOptions, handler ContextHandler[TData]) attemptHandler[TData] {
+	return func(deadline time.Time, msg message.Message[TData]) error {
+		if deadline.IsZero() {
+			deadline = time.Now().Add(c.handlerTimeout(options.HandlerTimeout))
+		}
+		info := MessageInfo{
+			Topic:         msg.Topic,
+			Partition:     msg.Partition,
+			Offset:        msg.Offset,
+			Key:           msg.Key,
+			CorrelationId: msg.CorrelationId,
+			Headers:       msg.Metadata,
+			Deadline:      deadline,
+		}
+
+		ctx, cancel := context.WithDeadline(context.WithValue(c.ctx, messageInfoKey{}, info), info.Deadline)
+		defer cancel()
+
+		return c.watch(ctx, options.Watchdog, info, func() error {
+			return handler(ctx, msg)
+		})
+	}
+}
+
+// handlerTimeout retorna o prazo de uma mensagem, compartilhado pelas suas tentativas: o informado nas opções, limitado a
+// handlerDeadlineShare% do max.poll.interval.ms da assinatura, que também é o prazo padrão
+func (c *kafkaConsumer[TData]) handlerTimeout(timeout time.Duration) time.Duration {
+	limit := c.maxPollInterval * handlerDeadlineShare / 100
+	if timeout <= 0 || (limit > 0 && timeout > limit) {
+		return limit
+	}
+	return timeout
+}
+
+// watch executa o handler sob o watchdog. Com WatchdogReport o handler atrasado é registrado em log e
+// aguardado; com WatchdogAbort ele é abandonado no prazo e a execução retorna ErrHandlerAborted.
+// No encerramento do consumidor o handler é sempre aguardado. Um pânico do handler é propagado
+// para a goroutine de consumo.
+func (c *kafkaConsumer[TData]) watch(ctx context.Context, action enums.WatchdogAction, info MessageInfo, run func() error) error {
+	if action != enums.WatchdogAbort {
+		report := time.AfterFunc(time.Until(info.Deadline), func() {
+			c.logger.Warn("Handler exceeded message deadline", messageInfoFields(info)...)
+		})
+		defer report.Stop()
+		return run()
+	}
+
+	done := make(chan execution, 1)
+	go func() {
+		result := execution{panicked: true}
+		defer func() {
+			if result.panicked {
+				result.panic = recover()
+			}
+			done <- result
+		}()
+		result.err = run()
+		result.panicked = false
+	}()
+
+	select {
+	case result := <-done:
+		return result.outcome()
+	case <-ctx.Done():
+	}
+
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		// Encerramento do consumidor: o handler observa o cancelamento pelo contexto e é aguardado
+		return (<-done).outcome()
+	}
+	c.logger.Error("Handler aborted by watchdog", messageInfoFields(info, logger.FieldError, ctx.Err())...)
+	return fmt.Errorf("%w: %w", ErrHandlerAborted, ctx.Err())
+}
+
+// ==========================================================================
+// Métodos execution
+// ==========================================================================
+
+// outcome retorna o erro do handler ou propaga o seu pânico
+func (e execution) outcome() error {
+	if e.panicked {
+		panic(e.panic)
+	}
+	return e.err
+}
+
+// ==========================================================================
+// Funções
+// ==========================================================================
+
+// MessageInfoFromContext retorna os metadados da mensagem do contexto recebido por um handler com contexto
+func MessageInfoFromContext(ctx context.Context) (MessageInfo, bool) {
+	info, ok := ctx.Value(messageInfoKey{}).(MessageInfo)
+	return info, ok
+}
+
+// messageInfoFields retorna os campos estruturados de log da mensagem em processamento
+func messageInfoFields(info MessageInfo, args ...any) []any {
+	fields := []any{
+		logger.FieldTopic, info.Topic,
+		logger.FieldPartition, info.Partition,
+		logger.FieldOffset, info.Offset,
+		logger.FieldCorrelationId, info.CorrelationId.String(),
+		"deadline", info.Deadline,
+	}
+	return append(fields, args...)
+}
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Teste do prazo do handler com contexto
+// O prazo padrão e máximo deixa margem antes do max.poll.interval.ms da assinatura.
+func TestHandlerTimeoutLimitedByPollInterval(t *testing.T) {
+	c := &kafkaConsumer[string]{maxPollInterval: 5 * time.Minute}
+
+	assert.Equal(t, 4*time.Minute, c.handlerTimeout(0), "Sem prazo informado deveria usar 80% do max.poll.interval.ms")
+	assert.Equal(t, 30*time.Second, c.handlerTimeout(30*time.Second))
+	assert.Equal(t, 4*time.Minute, c.handlerTimeout(10*time.Minute), "O prazo não deveria permitir exceder o max.poll.interval.ms")
+}
+
+// Teste do watchdog em modo ABORT
+// O handler que excede o prazo é abandonado e recebe o contexto expirado com os metadados da mensagem.
+func TestContextualAbortsHandlerAfterDeadline(t *testing.T) {
+	c := &kafkaConsumer[string]{ctx: context.Background(), logger: logger.NewNopLogger(), maxPollInterval: time.Minute}
+	options := ConsumeOptions{HandlerTimeout: 20 * time.Millisecond, Watchdog: enums.WatchdogAbort}
+
+	observed := make(chan MessageInfo, 1)
+	handler := c.contextual(options, func(ctx context.Context, msg message.Message[string]) error {
+		info, _ := MessageInfoFromContext(ctx)
+		observed <- info
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	started := time.Now()
+	err := handler(time.Time{}, message.Message[string]{Topic: "orders", Partition: 1, Offset: 42})
+	assert.ErrorIs(t, err, ErrHandlerAborted)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(started), time.Second, "O watchdog não deveria aguardar o handler atrasado")
+
+	info := <-observed
+	require.Equal(t, "orders", info.Topic)
+	assert.Equal(t, int32(1), info.Partition)
+	assert.Equal(t, int64(42), info.Offset)
+}
+
+// Teste da falha por abandono do watchdog
+// A mensagem abandonada não é repetida em memória, pois o handler abandonado pode continuar executando.
+func TestHandleMessageDoesNotRetryAbortedHandler(t *testing.T) {
+	c := &kafkaConsumer[string]{ctx: context.Background(), logger: logger.NewNopLogger()}
+	sub, err := newSubscription("orders", ConsumeOptions{RetryPolicy: RetryPolicy{MaxRetries: 3}}, c.logger)
+	require.NoError(t, err)
+
+	calls := 0
+	topic := "orders"
+	record := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic}}
+	err = c.handleMessage(sub, record, message.Message[string]{}, func(message.Message[string]) error {
+		calls++
+		return fmt.Errorf("%w: %w", ErrHandlerAborted, context.DeadlineExceeded)
+	})
+
+	assert.ErrorContains(t, err, "orders.dlq", "A mensagem deveria seguir direto para a dead-letter padrão")
+	assert.Equal(t, 1, calls, "O handler abandonado não deveria ser repetido pela RetryPolicy")
+}
+
+// Teste do prazo único por mensagem
+// As retentativas compartilham o prazo calculado ao receber a mensagem e param quando ele não comporta outra tentativa.
+func TestHandleWithinSharesDeadlineAcrossRetries(t *testing.T) {
+	c := &kafkaConsumer[string]{ctx: context.Background(), logger: logger.NewNopLogger(), maxPollInterval: time.Minute}
+	options := ConsumeOptions{HandlerTimeout: 200 * time.Millisecond, RetryPolicy: RetryPolicy{MaxRetries: 10, InitialBackoff: 10 * time.Millisecond}}
+	sub, err := newSubscription("orders", options, c.logger)
+	require.NoError(t, err)
+
+	var deadlines []time.Time
+	attempt := c.contextual(options, func(ctx context.Context, msg message.Message[string]) error {
+		info, _ := MessageInfoFromContext(ctx)
+		deadlines = append(deadlines, info.Deadline)
+		time.Sleep(60 * time.Millisecond)
+		return fmt.Errorf("falha")
+	})
+
+	topic := "orders"
+	record := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic}}
+	started := time.Now()
+	err = c.handleWithin(sub, record, message.Message[string]{}, c.handlerTimeout(options.HandlerTimeout), attempt)
+
+	assert.ErrorContains(t, err, "orders.dlq", "Esgotado o prazo a mensagem deveria seguir para a dead-letter")
+	assert.Less(t, time.Since(started), 300*time.Millisecond, "As retentativas não deveriam exceder o prazo da mensagem")
+	require.Greater(t, len(deadlines), 1)
+	assert.Less(t, len(deadlines), 11, "As retentativas deveriam parar antes de esgotar a RetryPolicy")
+	for _, deadline := range deadlines {
+		assert.Equal(t, deadlines[0], deadline, "Todas as tentativas deveriam receber o mesmo prazo")
+	}
+}
@@ -0,0 +1,261 @@
+package engine
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// ErrControllerInUse indica que o controlador já está associado a uma assinatura em execução
+var ErrControllerInUse = errors.New("controlador já associado a uma assinatura")
+
+// ErrSubscriptionNotRunning indica que o controlador não está associado a uma assinatura em execução
+var ErrSubscriptionNotRunning = errors.New("assinatura não está em execução")
+
+// ErrManualCommitDisabled indica que a assinatura não utiliza o modo de commit manual
+var ErrManualCommitDisabled = errors.New("assinatura não utiliza commit manual")
+
+// ErrNilController indica uma operação em um controlador nil, que não é associado a nenhuma assinatura
+var ErrNilController = errors.New("controlador não informado: crie-o com NewController e informe-o na assinatura")
+
+// Controller controla o ciclo de vida de uma assinatura em execução.
+// É criado pela aplicação, informado na assinatura e usado para encerrá-la de outra goroutine.
+type Controller struct {
+	mu       sync.Mutex
+	attached bool
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+	result   error
+	commands chan controlCommand
+	// requested mantém os offsets solicitados por Commit até o laço de consumo confirmá-los (nil fora do commit manual)
+	requested map[partitionKey]kafka.TopicPartition
+}
+
+// controlCommand é uma operação solicitada pela aplicação e executada na goroutine de consumo
+type controlCommand struct {
+	apply  func(client *kafka.Consumer, sub *subscription) error
+	result chan error
+}
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// NewController cria um controlador de assinatura
+func NewController() *Controller {
+	return &Controller{
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		commands: make(chan controlCommand),
+	}
+}
+
+// ==========================================================================
+// Métodos Públicos
+// ==========================================================================
+
+// Close solicita o encerramento da assinatura e aguarda sua conclusão: os handlers em andamento
+// terminam, os offsets finais são confirmados de forma síncrona e o consumidor deixa o grupo.
+// Retorna o erro que encerrou o consumo, se houver. Sem assinatura associada retorna imediatamente.
+func (c *Controller) Close() error {
+	if c == nil {
+		return ErrNilController
+	}
+
+	c.stopOnce.Do(func() { close(c.stop) })
+
+	c.mu.Lock()
+	attached := c.attached
+	c.mu.Unlock()
+
+	if !attached {
+		return nil
+	}
+
+	<-c.done
+	return c.result
+}
+
+// Pause interrompe a busca de mensagens das partições informadas sem deixar o grupo de consumidores.
+// Sem partições, pausa todas as partições atribuídas, inclusive as atribuídas em rebalanceamentos futuros.
+// As mensagens em processamento não são interrompidas. Retorna ErrSubscriptionNotRunning se a
+// assinatura não estiver em execução.
+func (c *Controller) Pause(partitions ...kafka.TopicPartition) error {
+	return c.send(func(client *kafka.Consumer, sub *subscription) error {
+		return sub.flow.apply(client, true, partitions, sub.held)
+	})
+}
+
+// Resume retoma a busca de mensagens das partições informadas ou, sem partições, de todas as partições.
+// Partições retidas por backpressure ou aguardando uma mensagem de retentativa só são retomadas
+// quando esses mecanismos as liberarem.
+func (c *Controller) Resume(partitions ...kafka.TopicPartition) error {
+	return c.send(func(client *kafka.Consumer, sub *subscription) error {
+		return sub.flow.apply(client, false, partitions, sub.held)
+	})
+}
+
+// Seek reposiciona as partições informadas no offset de cada uma. Aceita offsets absolutos e os
+// offsets lógicos kafka.OffsetBeginning e kafka.OffsetEnd. As partições devem estar atribuídas à
+// assinatura; mensagens já em processamento não são interrompidas.
+func (c *Controller) Seek(partitions ...kafka.TopicPartition) error {
+	return c.send(func(client *kafka.Consumer, sub *subscription) error {
+		for _, tp := range partitions {
+			if err := client.Seek(tp, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Commit solicita, no modo de commit manual, a confirmação das mensagens informadas nas posições em
+// que foram lidas: é confirmado o offset seguinte a cada uma. Pode ser chamado pelo handler ou por outra
+// goroutine; o laço de consumo confirma os offsets de forma síncrona logo em seguida e, se ainda
+// pendentes, na revogação das partições e no encerramento. Retorna ErrManualCommitDisabled fora do
+// commit manual, ErrSubscriptionNotRunning se a assinatura não estiver em execução e ErrNilController
+// para um controlador nil.
+func (c *Controller) Commit(messages ...kafka.TopicPartition) error {
+	if c == nil {
+		return ErrNilController
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return ErrSubscriptionNotRunning
+	default:
+	}
+	if !c.attached {
+		return ErrSubscriptionNotRunning
+	}
+	if c.requested == nil {
+		return ErrManualCommitDisabled
+	}
+
+	for _, tp := range messages {
+		next := tp
+		next.Offset++
+		c.requested[keyOf(tp)] = next
+	}
+	return nil
+}
+
+// Done retorna um canal fechado quando a assinatura termina
+func (c *Controller) Done() <-chan struct{} {
+	return c.done
+}
+
+// ==========================================================================
+// Métodos Privados
+// ==========================================================================
+
+// attach associa o controlador a uma assinatura. Um controlador nil é ignorado.
+func (c *Controller) attach() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.attached {
+		return ErrControllerInUse
+	}
+	c.attached = true
+	return nil
+}
+
+// stopping retorna o canal fechado quando o encerramento é solicitado (nil para controlador nil)
+func (c *Controller) stopping() <-chan struct{} {
+	if c == nil {
+		return nil
+	}
+	return c.stop
+}
+
+// control retorna o canal de operações solicitadas pela aplicação (nil para controlador nil)
+func (c *Controller) control() <-chan controlCommand {
+	if c == nil {
+		return nil
+	}
+	return c.commands
+}
+
+// send entrega a operação ao laço de consumo e aguarda o resultado
+func (c *Controller) send(apply func(client *kafka.Consumer, sub *subscription) error) error {
+	if c == nil {
+		return ErrNilController
+	}
+
+	c.mu.Lock()
+	attached := c.attached
+	c.mu.Unlock()
+
+	if !attached {
+		return ErrSubscriptionNotRunning
+	}
+
+	command := controlCommand{apply: apply, result: make(chan error, 1)}
+	select {
+	case c.commands <- command:
+	case <-c.done:
+		return ErrSubscriptionNotRunning
+	}
+
+	return <-command.result
+}
+
+// enableCommits habilita as solicitações de Commit do modo de commit manual
+func (c *Controller) enableCommits() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requested = make(map[partitionKey]kafka.TopicPartition)
+}
+
+// takeCommits retira os offsets solicitados por Commit das partições informadas ou, sem partições,
+// de todas as partições (nil para controlador nil)
+func (c *Controller) takeCommits(partitions []kafka.TopicPartition) []kafka.TopicPartition {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var offsets []kafka.TopicPartition
+	take := func(key partitionKey) {
+		if tp, exists := c.requested[key]; exists {
+			offsets = append(offsets, tp)
+			delete(c.requested, key)
+		}
+	}
+
+	if partitions == nil {
+		for key := range c.requested {
+			take(key)
+		}
+		return offsets
+	}
+	for _, tp := range partitions {
+		take(keyOf(tp))
+	}
+	return offsets
+}
+
+// finish registra o término da assinatura e libera as chamadas de Close
+func (c *Controller) finish(err error) {
+	if c == nil {
+		return
+	}
+	c.result = err
+	close(c.done)
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+// Teste do ciclo de vida do controlador
+// Close sem assinatura retorna imediatamente; com assinatura aguarda o término e devolve o erro do consumo.
+func TestControllerLifecycle(t *testing.T) {
+	idle := NewController()
+	assert.NoError(t, idle.Close())
+
+	controller := NewController()
+	assert.NoError(t, controller.attach())
+	assert.ErrorIs(t, controller.attach(), ErrControllerInUse)
+
+	cause := errors.New("falha")
+	go func() {
+		<-controller.stopping()
+		controller.finish(cause)
+	}()
+
+	assert.ErrorIs(t, controller.Close(), cause)
+	assert.ErrorIs(t, controller.Close(), cause)
+	<-controller.Done()
+
+	var none *Controller
+	assert.NoError(t, none.attach())
+	assert.Nil(t, none.stopping())
+	assert.ErrorIs(t, none.Commit(kafka.TopicPartition{}), ErrNilController, "Commit sem controlador deveria retornar erro")
+	assert.ErrorIs(t, none.Pause(), ErrNilController)
+	assert.ErrorIs(t, none.Close(), ErrNilController)
+}
+
+// Teste das solicitações de pausa e retomada
+// Sem assinatura em execução retornam erro; em execução são entregues ao laço de consumo.
+func TestControllerPauseAndResume(t *testing.T) {
+	controller := NewController()
+	assert.ErrorIs(t, controller.Pause(), ErrSubscriptionNotRunning)
+
+	assert.NoError(t, controller.attach())
+	go func() {
+		// Simula o laço de consumo atendendo duas solicitações
+		for _, result := range []error{nil, errors.New("falha ao retomar")} {
+			command := <-controller.control()
+			command.result <- result
+		}
+	}()
+
+	topic := "orders"
+	assert.NoError(t, controller.Pause(kafka.TopicPartition{Topic: &topic, Partition: 1}))
+	assert.EqualError(t, controller.Resume(), "falha ao retomar")
+
+	controller.finish(nil)
+	assert.ErrorIs(t, controller.Resume(), ErrSubscriptionNotRunning)
+}
+
+// Teste das solicitações de commit manual
+// O offset seguinte à mensagem é retirado pelo laço de consumo uma única vez, por partição.
+func TestControllerManualCommit(t *testing.T) {
+	topic := "orders"
+	controller := NewController()
+	assert.NoError(t, controller.attach())
+	assert.ErrorIs(t, controller.Commit(kafka.TopicPartition{Topic: &topic}), ErrManualCommitDisabled)
+
+	controller.enableCommits()
+	assert.NoError(t, controller.Commit(kafka.TopicPartition{Topic: &topic, Partition: 0, Offset: 9}))
+	assert.NoError(t, controller.Commit(kafka.TopicPartition{Topic: &topic, Partition: 1, Offset: 4}))
+
+	revoked := controller.takeCommits([]kafka.TopicPartition{{Topic: &topic, Partition: 1}})
+	assert.Equal(t, "orders[1]", partitionsText(revoked))
+	assert.Equal(t, kafka.Offset(5), revoked[0].Offset)
+
+	remaining := controller.takeCommits(nil)
+	assert.Equal(t, kafka.Offset(10), remaining[0].Offset)
+	assert.Empty(t, controller.takeCommits(nil))
+
+	controller.finish(nil)
+	assert.ErrorIs(t, controller.Commit(kafka.TopicPartition{Topic: &topic}), ErrSubscriptionNotRunning)
+}
+
+// Teste dos limites padrão de backpressure
+func TestBackpressureDefaults(t *testing.T) {
+	assert.Equal(t, BackpressureOptions{HighWatermark: 40, LowWatermark: 20}, BackpressureOptions{}.withDefaults(40))
+	assert.Equal(t, BackpressureOptions{HighWatermark: 100, LowWatermark: 10}, BackpressureOptions{HighWatermark: 100, LowWatermark: 10}.withDefaults(40))
+	assert.Equal(t, BackpressureOptions{HighWatermark: 10, LowWatermark: 5}, BackpressureOptions{HighWatermark: 10, LowWatermark: 30}.withDefaults(40))
+}
@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Cabeçalhos da Dead-Letter
+// ==========================================================================
+
+// Cabeçalhos adicionados às mensagens encaminhadas para o tópico de dead-letter
+const (
+	// DeadLetterHeaderError contém a mensagem do erro que causou o encaminhamento
+	DeadLetterHeaderError = "x-dlq-error"
+	// DeadLetterHeaderTopic contém o tópico de origem da mensagem
+	DeadLetterHeaderTopic = "x-dlq-original-topic"
+	// DeadLetterHeaderPartition contém a partição de origem da mensagem, no tópico principal quando ela vem de um tópico de retentativa
+	DeadLetterHeaderPartition = "x-dlq-original-partition"
+	// DeadLetterHeaderOffset contém o offset de origem da mensagem, no tópico principal quando ela vem de um tópico de retentativa
+	DeadLetterHeaderOffset = "x-dlq-original-offset"
+	// DeadLetterHeaderAttempts contém o número de tentativas de processamento realizadas
+	DeadLetterHeaderAttempts = "x-dlq-attempts"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// forwarder publica as mensagens encaminhadas pelo consumidor e limita a espera pela confirmação do broker
+type forwarder struct {
+	ctx      context.Context // Contexto do consumidor: o encerramento interrompe a espera
+	producer *kafka.Producer
+	timeout  time.Duration // message.timeout.ms do produtor (0 = padrão do librdkafka)
+}
+
+// defaultDeliveryTimeout é o message.timeout.ms padrão do librdkafka
+const defaultDeliveryTimeout = 300 * time.Second
+
+// deliveryReportMargin é a folga sobre o message.timeout.ms para o relatório de entrega chegar ao consumidor
+const deliveryReportMargin = 5 * time.Second
+
+// ==========================================================================
+// Métodos forwarder
+// ==========================================================================
+
+// deliver publica o registro e aguarda o relatório de entrega até o message.timeout.ms do produtor
+// ou o encerramento do consumidor. Sem confirmação retorna erro, e a mensagem de origem não é confirmada.
+func (f forwarder) deliver(record *kafka.Message, kind string) error {
+	topic := topicOf(record)
+	if f.producer == nil {
+		return fmt.Errorf("produtor indisponível para encaminhar mensagem ao tópico %s %s", kind, topic)
+	}
+
+	deliveryChan := make(chan kafka.Event, 1)
+	if err := f.producer.Produce(record, deliveryChan); err != nil {
+		return fmt.Errorf("falha ao publicar no tópico %s %s: %w", kind, topic, err)
+	}
+
+	timeout := f.timeout
+	if timeout <= 0 {
+		timeout = defaultDeliveryTimeout
+	}
+	timer := time.NewTimer(timeout + deliveryReportMargin)
+	defer timer.Stop()
+
+	var event kafka.Event
+	select {
+	case event = <-deliveryChan:
+	case <-f.ctx.Done():
+		return fmt.Errorf("entrega ao tópico %s %s interrompida: %w", kind, topic, f.ctx.Err())
+	case <-timer.C:
+		return fmt.Errorf("entrega ao tópico %s %s não confirmada em %s", kind, topic, timeout+deliveryReportMargin)
+	}
+
+	delivered, ok := event.(*kafka.Message)
+	if !ok {
+		return fmt.Errorf("relatório de entrega inesperado do tópico %s %s", kind, topic)
+	}
+	if delivered.TopicPartition.Error != nil {
+		return fmt.Errorf("falha na entrega ao tópico %s %s: %w", kind, topic, delivered.TopicPartition.Error)
+	}
+	return nil
+}
+
+// ==========================================================================
+// Funções
+// ==========================================================================
+
+// forwardToDeadLetter publica a mensagem original no tópico de dead-letter e aguarda a confirmação do broker
+func forwardToDeadLetter(target forwarder, deadLetterTopic string, record *kafka.Message, cause error, attempts int) error {
+	return target.deliver(deadLetterRecord(deadLetterTopic, record, cause, attempts), "de dead-letter")
+}
+
+// deadLetterRecord monta o registro da dead-letter a partir da mensagem original.
+// Chave, valor e cabeçalhos originais são preservados; os cabeçalhos de diagnóstico são acrescentados.
+func deadLetterRecord(deadLetterTopic string, record *kafka.Message, cause error, attempts int) *kafka.Message {
+	partition, offset := originalPosition(record)
+
+	headers := make([]kafka.Header, 0, len(record.Headers)+5)
+	headers = append(headers, record.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: DeadLetterHeaderError, Value: []byte(errorText(cause))},
+		kafka.Header{Key: DeadLetterHeaderTopic, Value: []byte(originalTopic(record))},
+		kafka.Header{Key: DeadLetterHeaderPartition, Value: []byte(strconv.Itoa(int(partition)))},
+		kafka.Header{Key: DeadLetterHeaderOffset, Value: []byte(strconv.FormatInt(int64(offset), 10))},
+		kafka.Header{Key: DeadLetterHeaderAttempts, Value: []byte(strconv.Itoa(attempts))},
+	)
+
+	return &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &deadLetterTopic, Partition: kafka.PartitionAny},
+		Key:            record.Key,
+		Value:          record.Value,
+		Headers:        headers,
+	}
+}
+
+// topicOf retorna o tópico de uma mensagem Kafka ou string vazia
+func topicOf(record *kafka.Message) string {
+	if record.TopicPartition.Topic == nil {
+		return ""
+	}
+	return *record.TopicPartition.Topic
+}
+
+// originalTopic retorna o tópico principal da mensagem, considerando o cabeçalho
+// dos tópicos de retentativa quando presente
+func originalTopic(record *kafka.Message) string {
+	for _, header := range record.Headers {
+		if header.Key == RetryHeaderOriginalTopic {
+			return string(header.Value)
+		}
+	}
+	return topicOf(record)
+}
+
+// originalPosition retorna a partição e o offset da mensagem no tópico principal, considerando os
+// cabeçalhos dos tópicos de retentativa quando presentes
+func originalPosition(record *kafka.Message) (int32, kafka.Offset) {
+	partition, offset := record.TopicPartition.Partition, record.TopicPartition.Offset
+	for _, header := range record.Headers {
+		switch header.Key {
+		case RetryHeaderOriginalPartition:
+			if value, err := strconv.ParseInt(string(header.Value), 10, 32); err == nil {
+				partition = int32(value)
+			}
+		case RetryHeaderOriginalOffset:
+			if value, err := strconv.ParseInt(string(header.Value), 10, 64); err == nil {
+				offset = kafka.Offset(value)
+			}
+		}
+	}
+	return partition, offset
+}
+
+// errorText retorna a descrição do erro ou string vazia
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
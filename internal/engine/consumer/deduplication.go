@@ -0,0 +1,160 @@
+package engine
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/google/uuid"
+)
+
+// ==========================================================================
+// Interfaces
+// ==========================================================================
+
+// IDeduplicationStore registra as chaves das mensagens já processadas por uma assinatura.
+// Implementações devem ser seguras para uso concorrente, pois o consumo paralelo as consulta
+// a partir de vários workers.
+type IDeduplicationStore interface {
+	// Contains indica se a chave já foi registrada como processada
+	Contains(ctx context.Context, key string) (bool, error)
+
+	// Add registra a chave como processada
+	Add(ctx context.Context, key string) error
+}
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// DeduplicationOptions habilita a deduplicação de uma assinatura
+type DeduplicationOptions struct {
+	// Store guarda as chaves já processadas (nil desabilita a deduplicação)
+	Store IDeduplicationStore
+	// Key define de onde a chave de cada mensagem é extraída (padrão: CorrelationId)
+	Key DeduplicationKey
+}
+
+// DeduplicationKey define de onde a chave de deduplicação de uma mensagem é extraída.
+// O valor zero usa o CorrelationId; mensagens sem CorrelationId válido não são deduplicadas.
+type DeduplicationKey struct {
+	source deduplicationSource
+	header string
+}
+
+// deduplicationSource identifica a origem da chave de deduplicação
+type deduplicationSource int
+
+const (
+	deduplicationByCorrelationId deduplicationSource = iota
+	deduplicationByHeader
+	deduplicationByKey
+)
+
+// correlationIdHeader é o cabeçalho que transporta o CorrelationId da mensagem
+const correlationIdHeader = "correlationId"
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// DeduplicateByCorrelationId usa o cabeçalho correlationId como chave de deduplicação
+func DeduplicateByCorrelationId() DeduplicationKey {
+	return DeduplicationKey{source: deduplicationByCorrelationId}
+}
+
+// DeduplicateByHeader usa o valor do cabeçalho informado como chave de deduplicação
+func DeduplicateByHeader(name string) DeduplicationKey {
+	return DeduplicationKey{source: deduplicationByHeader, header: name}
+}
+
+// DeduplicateByKey usa a chave do registro Kafka como chave de deduplicação
+func DeduplicateByKey() DeduplicationKey {
+	return DeduplicationKey{source: deduplicationByKey}
+}
+
+// ==========================================================================
+// Métodos DeduplicationKey
+// ==========================================================================
+
+// of retorna a chave de deduplicação do registro, ou false quando o registro não a possui
+func (k DeduplicationKey) of(record *kafka.Message) (string, bool) {
+	switch k.source {
+	case deduplicationByKey:
+		return string(record.Key), len(record.Key) > 0
+	case deduplicationByHeader:
+		return headerValue(record, k.header)
+	default:
+		return correlationIdOf(record)
+	}
+}
+
+// ==========================================================================
+// Métodos Privados
+// ==========================================================================
+
+// duplicate consulta o store de deduplicação da assinatura. Retorna a chave do registro, vazia quando
+// a deduplicação não se aplica, e se a mensagem já foi processada.
+func (c *kafkaConsumer[TData]) duplicate(sub *subscription, record *kafka.Message) (string, bool, error) {
+	dedup := sub.options.Deduplication
+	if dedup.Store == nil {
+		return "", false, nil
+	}
+
+	key, ok := dedup.Key.of(record)
+	if !ok {
+		return "", false, nil
+	}
+
+	seen, err := dedup.Store.Contains(c.ctx, key)
+	if err != nil {
+		c.logger.Error("Error on check deduplication store", recordFields(record, "deduplicationKey", key, logger.FieldError, err)...)
+		return "", false, err
+	}
+	if seen {
+		c.logger.Info("Duplicate message skipped", recordFields(record, "deduplicationKey", key)...)
+	}
+	return key, seen, nil
+}
+
+// remember registra no store de deduplicação a chave de uma mensagem tratada.
+// A falha no registro apenas é reportada: a mensagem já foi tratada e segue confirmada.
+func (c *kafkaConsumer[TData]) remember(sub *subscription, record *kafka.Message, key string) {
+	if key == "" {
+		return
+	}
+
+	// O registro deve concluir mesmo que o consumidor esteja encerrando
+	if err := sub.options.Deduplication.Store.Add(context.WithoutCancel(c.ctx), key); err != nil {
+		c.logger.Warn("Error on record deduplication key", recordFields(record, "deduplicationKey", key, logger.FieldError, err)...)
+	}
+}
+
+// ==========================================================================
+// Funções
+// ==========================================================================
+
+// correlationIdOf retorna o CorrelationId do registro. Valores inválidos e o UUID nulo não identificam
+// a mensagem, pois o consumidor os substitui por um identificador novo, e não são usados como chave.
+func correlationIdOf(record *kafka.Message) (string, bool) {
+	value, ok := headerValue(record, correlationIdHeader)
+	if !ok {
+		return "", false
+	}
+	correlationId, err := uuid.Parse(value)
+	if err != nil || correlationId == uuid.Nil {
+		return "", false
+	}
+	return correlationId.String(), true
+}
+
+// headerValue retorna o valor não vazio do cabeçalho informado, sem diferenciar maiúsculas e minúsculas
+func headerValue(record *kafka.Message, name string) (string, bool) {
+	for _, header := range record.Headers {
+		if strings.EqualFold(header.Key, name) && len(header.Value) > 0 {
+			return string(header.Value), true
+		}
+	}
+	return "", false
+}
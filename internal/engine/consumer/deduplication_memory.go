@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// defaultDeduplicationCapacity é a quantidade de chaves mantidas pelo store em memória quando não informada
+const defaultDeduplicationCapacity = 100000
+
+// memoryDeduplicationStore mantém em memória as chaves mais recentes, descartando as menos usadas
+// ao atingir a capacidade e as expiradas após o TTL
+type memoryDeduplicationStore struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[string]*list.Element
+	recent   *list.List // Chaves da mais recente (frente) para a menos recente
+}
+
+// deduplicationEntry é uma chave do store em memória e o seu vencimento
+type deduplicationEntry struct {
+	key       string
+	expiresAt time.Time // Zero quando a chave não expira
+}
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// NewMemoryDeduplicationStore cria um store de deduplicação em memória com política LRU e TTL.
+// Com capacity <= 0 são mantidas até 100000 chaves; com ttl <= 0 as chaves não expiram.
+// As chaves são perdidas quando o processo reinicia.
+func NewMemoryDeduplicationStore(capacity int, ttl time.Duration) IDeduplicationStore {
+	if capacity <= 0 {
+		capacity = defaultDeduplicationCapacity
+	}
+	return &memoryDeduplicationStore{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[string]*list.Element),
+		recent:   list.New(),
+	}
+}
+
+// ==========================================================================
+// Métodos Públicos
+// ==========================================================================
+
+// Contains indica se a chave está no store e ainda não expirou
+func (s *memoryDeduplicationStore) Contains(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return false, nil
+	}
+
+	entry := element.Value.(*deduplicationEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		s.remove(element)
+		return false, nil
+	}
+
+	s.recent.MoveToFront(element)
+	return true, nil
+}
+
+// Add registra a chave, renovando o seu vencimento, e descarta as chaves menos usadas além da capacidade
+func (s *memoryDeduplicationStore) Add(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expiresAt time.Time
+	if s.ttl > 0 {
+		expiresAt = time.Now().Add(s.ttl)
+	}
+
+	if element, ok := s.entries[key]; ok {
+		element.Value.(*deduplicationEntry).expiresAt = expiresAt
+		s.recent.MoveToFront(element)
+		return nil
+	}
+
+	s.entries[key] = s.recent.PushFront(&deduplicationEntry{key: key, expiresAt: expiresAt})
+	for s.recent.Len() > s.capacity {
+		s.remove(s.recent.Back())
+	}
+	return nil
+}
+
+// ==========================================================================
+// Métodos Privados
+// ==========================================================================
+
+// remove descarta a chave do store
+func (s *memoryDeduplicationStore) remove(element *list.Element) {
+	s.recent.Remove(element)
+	delete(s.entries, element.Value.(*deduplicationEntry).key)
+}
@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/internal/common/sqlquery"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// SQLDeduplicationOptions configura o store de deduplicação em banco de dados
+type SQLDeduplicationOptions struct {
+	// Table é a tabela das chaves processadas (padrão: kafka_deduplication), com as colunas
+	// dedup_key (texto, chave primária) e processed_at (inteiro, milissegundos Unix)
+	Table string
+	// TTL define por quanto tempo uma chave é considerada processada (0 = sem expiração)
+	TTL time.Duration
+	// Placeholder define o marcador de parâmetros do driver (padrão: "?")
+	Placeholder enums.SQLPlaceholder
+}
+
+// defaultDeduplicationTable é a tabela usada pelo store em banco quando não informada
+const defaultDeduplicationTable = "kafka_deduplication"
+
+// sqlDeduplicationStore guarda as chaves processadas em uma tabela acessada por database/sql
+type sqlDeduplicationStore struct {
+	db          *sql.DB
+	ttl         time.Duration
+	selectQuery string
+	updateQuery string
+	insertQuery string
+}
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// NewSQLDeduplicationStore cria um store de deduplicação na tabela informada, compartilhável entre as
+// instâncias de um mesmo grupo de consumidores. A tabela deve existir, por exemplo:
+//
+//	CREATE TABLE kafka_deduplication (
+//	    dedup_key    VARCHAR(512) PRIMARY KEY,
+//	    processed_at BIGINT NOT NULL
+//	)
+//
+// As chaves expiradas não são removidas automaticamente; apague periodicamente as linhas com
+// processed_at anterior ao TTL.
+func NewSQLDeduplicationStore(db *sql.DB, options SQLDeduplicationOptions) (IDeduplicationStore, error) {
+	if db == nil {
+		return nil, errors.New("conexão de banco de dados não informada para o store de deduplicação")
+	}
+
+	table := options.Table
+	if table == "" {
+		table = defaultDeduplicationTable
+	}
+	if err := sqlquery.ValidateTable(table); err != nil {
+		return nil, err
+	}
+
+	return &sqlDeduplicationStore{
+		db:          db,
+		ttl:         options.TTL,
+		selectQuery: sqlquery.Rebind(options.Placeholder, fmt.Sprintf("SELECT processed_at FROM %s WHERE dedup_key = ?", table)),
+		updateQuery: sqlquery.Rebind(options.Placeholder, fmt.Sprintf("UPDATE %s SET processed_at = ? WHERE dedup_key = ?", table)),
+		insertQuery: sqlquery.Rebind(options.Placeholder, fmt.Sprintf("INSERT INTO %s (dedup_key, processed_at) VALUES (?, ?)", table)),
+	}, nil
+}
+
+// ==========================================================================
+// Métodos Públicos
+// ==========================================================================
+
+// Contains indica se a chave foi registrada e, com TTL, se o registro ainda não expirou
+func (s *sqlDeduplicationStore) Contains(ctx context.Context, key string) (bool, error) {
+	var processedAt int64
+	err := s.db.QueryRowContext(ctx, s.selectQuery, key).Scan(&processedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("falha ao consultar chave de deduplicação: %w", err)
+	}
+
+	if s.ttl > 0 && time.Since(time.UnixMilli(processedAt)) > s.ttl {
+		return false, nil
+	}
+	return true, nil
+}
+
+// Add registra a chave, atualizando o instante de processamento quando ela já existe.
+// A atualização e a inserção não são atômicas: se outra instância registrar a mesma chave entre elas,
+// a inserção viola a chave primária e a linha inserida pela outra instância é atualizada.
+func (s *sqlDeduplicationStore) Add(ctx context.Context, key string) error {
+	now := time.Now().UnixMilli()
+
+	updated, err := s.touch(ctx, key, now)
+	if err != nil || updated {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, s.insertQuery, key, now); err != nil {
+		if updated, touchErr := s.touch(ctx, key, now); touchErr == nil && updated {
+			return nil
+		}
+		return fmt.Errorf("falha ao registrar chave de deduplicação: %w", err)
+	}
+	return nil
+}
+
+// ==========================================================================
+// Métodos Privados
+// ==========================================================================
+
+// touch atualiza o instante de processamento da chave e indica se ela já estava registrada
+func (s *sqlDeduplicationStore) touch(ctx context.Context, key string, now int64) (bool, error) {
+	result, err := s.db.ExecContext(ctx, s.updateQuery, now, key)
+	if err != nil {
+		return false, fmt.Errorf("falha ao atualizar chave de deduplicação: %w", err)
+	}
+	updated, err := result.RowsAffected()
+	return err == nil && updated > 0, nil
+}
@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// Teste da extração da chave de deduplicação
+// Sem a chave configurada no registro a deduplicação não se aplica.
+func TestDeduplicationKeyOf(t *testing.T) {
+	record := &kafka.Message{
+		Key:     []byte("pedido-1"),
+		Headers: []kafka.Header{{Key: "CorrelationId", Value: []byte("5f0c2a52-9d4e-4c1b-8a2e-3f6b7c8d9e01")}, {Key: "x-event-id", Value: []byte("evt-9")}},
+	}
+
+	key, ok := DeduplicationKey{}.of(record)
+	assert.True(t, ok)
+	assert.Equal(t, "5f0c2a52-9d4e-4c1b-8a2e-3f6b7c8d9e01", key, "O valor zero deveria usar o CorrelationId")
+
+	key, _ = DeduplicateByHeader("x-event-id").of(record)
+	assert.Equal(t, "evt-9", key)
+
+	key, _ = DeduplicateByKey().of(record)
+	assert.Equal(t, "pedido-1", key)
+
+	_, ok = DeduplicateByHeader("x-missing").of(record)
+	assert.False(t, ok)
+
+	for _, value := range []string{uuid.Nil.String(), "abc"} {
+		_, ok = DeduplicateByCorrelationId().of(&kafka.Message{Headers: []kafka.Header{{Key: "correlationId", Value: []byte(value)}}})
+		assert.False(t, ok, "CorrelationId nulo ou inválido não deveria ser usado como chave")
+	}
+}
+
+// Teste da deduplicação no tratamento da mensagem
+// Mensagens sem CorrelationId válido chegam sempre ao handler, e as repetidas com CorrelationId são descartadas.
+func TestHandleMessageDeduplicatesOnlyIdentifiedMessages(t *testing.T) {
+	c := &kafkaConsumer[string]{ctx: context.Background(), logger: logger.NewNopLogger()}
+	sub, err := newSubscription("orders", ConsumeOptions{
+		Deduplication: DeduplicationOptions{Store: NewMemoryDeduplicationStore(0, 0)},
+	}, c.logger)
+	require.NoError(t, err)
+
+	calls := 0
+	handler := func(message.Message[string]) error {
+		calls++
+		return nil
+	}
+	record := func(correlationId string) *kafka.Message {
+		topic := "orders"
+		return &kafka.Message{
+			TopicPartition: kafka.TopicPartition{Topic: &topic},
+			Headers:        []kafka.Header{{Key: "correlationId", Value: []byte(correlationId)}},
+		}
+	}
+
+	require.NoError(t, c.handleMessage(sub, record(uuid.Nil.String()), message.Message[string]{}, handler))
+	require.NoError(t, c.handleMessage(sub, record(uuid.Nil.String()), message.Message[string]{}, handler))
+	assert.Equal(t, 2, calls, "Mensagens com CorrelationId nulo não deveriam ser tratadas como duplicadas")
+
+	identified := uuid.NewString()
+	require.NoError(t, c.handleMessage(sub, record(identified), message.Message[string]{}, handler))
+	require.NoError(t, c.handleMessage(sub, record(identified), message.Message[string]{}, handler))
+	assert.Equal(t, 3, calls, "A mensagem repetida deveria ser descartada")
+}
+
+// Teste do store em memória
+// As chaves menos usadas são descartadas ao atingir a capacidade e as expiradas deixam de ser duplicadas.
+func TestMemoryDeduplicationStoreEvictsAndExpires(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryDeduplicationStore(2, 0)
+
+	require.NoError(t, store.Add(ctx, "a"))
+	require.NoError(t, store.Add(ctx, "b"))
+	seen, _ := store.Contains(ctx, "a") // "a" passa a ser a mais recente
+	assert.True(t, seen)
+
+	require.NoError(t, store.Add(ctx, "c"))
+	seen, _ = store.Contains(ctx, "b")
+	assert.False(t, seen, "A chave menos usada deveria ser descartada")
+	seen, _ = store.Contains(ctx, "a")
+	assert.True(t, seen)
+
+	expiring := NewMemoryDeduplicationStore(0, 10*time.Millisecond)
+	require.NoError(t, expiring.Add(ctx, "a"))
+	time.Sleep(20 * time.Millisecond)
+	seen, _ = expiring.Contains(ctx, "a")
+	assert.False(t, seen, "A chave expirada não deveria ser considerada processada")
+}
+
+// Teste do store em banco de dados com SQLite
+// A chave registrada é encontrada e o registro repetido atualiza a linha existente.
+func TestSQLDeduplicationStore(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec("CREATE TABLE kafka_deduplication (dedup_key VARCHAR(512) PRIMARY KEY, processed_at BIGINT NOT NULL)")
+	require.NoError(t, err)
+
+	store, err := NewSQLDeduplicationStore(db, SQLDeduplicationOptions{TTL: time.Hour})
+	require.NoError(t, err)
+
+	seen, err := store.Contains(ctx, "abc")
+	require.NoError(t, err)
+	assert.False(t, seen)
+
+	require.NoError(t, store.Add(ctx, "abc"))
+	require.NoError(t, store.Add(ctx, "abc"), "O registro repetido não deveria violar a chave primária")
+
+	seen, err = store.Contains(ctx, "abc")
+	require.NoError(t, err)
+	assert.True(t, seen)
+
+	_, err = NewSQLDeduplicationStore(db, SQLDeduplicationOptions{Table: "dedup; DROP TABLE x"})
+	assert.Error(t, err, "Nomes de tabela inválidos deveriam ser rejeitados")
+}
+
+// Teste do registro concorrente da mesma chave no store em banco de dados
+// Instâncias que registram a mesma chave ao mesmo tempo não falham pela violação da chave primária.
+func TestSQLDeduplicationStoreConcurrentAdd(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "dedup.db")+"?_pragma=busy_timeout(5000)")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(8)
+
+	_, err = db.Exec("CREATE TABLE kafka_deduplication (dedup_key VARCHAR(512) PRIMARY KEY, processed_at BIGINT NOT NULL)")
+	require.NoError(t, err)
+
+	store, err := NewSQLDeduplicationStore(db, SQLDeduplicationOptions{})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8*50)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range 50 {
+				errs <- store.Add(ctx, strconv.Itoa(key))
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err, "O registro concorrente da mesma chave não deveria falhar")
+	}
+}
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// DeserializationError indica que uma mensagem não pôde ser deserializada.
+// É retornado pelo consumo quando a estratégia encerra o consumidor.
+type DeserializationError struct {
+	Topic     string
+	Partition int32
+	Offset    kafka.Offset
+	Err       error
+}
+
+// DeserializationFailureCallback recebe a mensagem Kafka original que não pôde ser deserializada.
+// Retornar erro encerra o consumo com esse erro.
+type DeserializationFailureCallback func(record *kafka.Message, err error) error
+
+// deserializationFailure reúne o contexto de uma falha de deserialização
+type deserializationFailure struct {
+	forwarder forwarder
+	sub       *subscription
+	record    *kafka.Message
+	err       *DeserializationError
+	logger    logger.ILogger
+}
+
+// Mapa das estratégias de deserialização.
+// Cada estratégia retorna nil quando a mensagem deve ser descartada sem chamar o handler
+// (e confirmada), ou um erro que encerra o consumo.
+var deserializationStrategyMap = map[enums.DeserializationStrategy]func(failure deserializationFailure) error{
+	enums.OnDeserializationFailedStopHost: func(failure deserializationFailure) error {
+		return failure.err
+	},
+	enums.OnDeserializationIgnoreMessage: func(failure deserializationFailure) error {
+		failure.logger.Warn("Error on deserialize message, ignoring", recordFields(failure.record, logger.FieldError, failure.err.Err)...)
+		return nil
+	},
+	enums.OnDeserializationFailedSendToDeadLetter: func(failure deserializationFailure) error {
+		deadLetterTopic := failure.sub.deadLetterTopic(failure.record)
+		err := forwardToDeadLetter(failure.forwarder, deadLetterTopic, failure.record, failure.err, 0)
+		if err != nil {
+			return fmt.Errorf("%w: %v", failure.err, err)
+		}
+		failure.logger.Warn("Undecodable message forwarded to dead-letter topic", recordFields(failure.record, "deadLetterTopic", deadLetterTopic, logger.FieldError, failure.err.Err)...)
+		return nil
+	},
+	enums.OnDeserializationFailedInvokeCallback: func(failure deserializationFailure) error {
+		callback := failure.sub.options.DeserializationFailureCallback
+		if callback == nil {
+			return fmt.Errorf("%w: callback de falha de deserialização não configurado", failure.err)
+		}
+		return callback(failure.record, failure.err)
+	},
+}
+
+// ==========================================================================
+// Métodos
+// ==========================================================================
+
+// Error implementa a interface error
+func (e *DeserializationError) Error() string {
+	return fmt.Sprintf("falha ao deserializar mensagem %s[%d]@%v: %v", e.Topic, e.Partition, e.Offset, e.Err)
+}
+
+// Unwrap retorna a causa da falha
+func (e *DeserializationError) Unwrap() error {
+	return e.Err
+}
+
+// ==========================================================================
+// Funções
+// ==========================================================================
+
+// applyDeserializationStrategy aplica a estratégia configurada a uma falha de deserialização.
+// Estratégias desconhecidas encerram o consumo, como OnDeserializationFailedStopHost.
+func applyDeserializationStrategy(strategy enums.DeserializationStrategy, failure deserializationFailure) error {
+	strategyFunc, exists := deserializationStrategyMap[strategy]
+	if !exists {
+		return failure.err
+	}
+	return strategyFunc(failure)
+}
+
+// newDeserializationError cria o erro de deserialização de uma mensagem Kafka
+func newDeserializationError(record *kafka.Message, err error) *DeserializationError {
+	return &DeserializationError{
+		Topic:     topicOf(record),
+		Partition: record.TopicPartition.Partition,
+		Offset:    record.TopicPartition.Offset,
+		Err:       err,
+	}
+}
@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFailure prepara a falha de deserialização de um registro do tópico orders
+func newFailure(t *testing.T, producer *kafka.Producer, options ConsumeOptions) deserializationFailure {
+	sub, err := newSubscription("orders", options, logger.NewNopLogger())
+	require.NoError(t, err)
+
+	topic := "orders"
+	record := &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 2, Offset: 41},
+		Key:            []byte("pedido-1"),
+		Value:          []byte("{inválido"),
+		Headers:        []kafka.Header{{Key: "source", Value: []byte("api")}},
+	}
+	return deserializationFailure{
+		forwarder: forwarder{ctx: context.Background(), producer: producer},
+		sub:       sub,
+		record:    record,
+		err:       newDeserializationError(record, errors.New("payload inválido")),
+		logger:    logger.NewNopLogger(),
+	}
+}
+
+// Teste das estratégias que não publicam mensagens
+// StopHost encerra com o erro tipado, Ignore descarta e InvokeCallback entrega o registro original ao callback.
+func TestDeserializationStrategies(t *testing.T) {
+	failure := newFailure(t, nil, ConsumeOptions{})
+
+	err := applyDeserializationStrategy(enums.OnDeserializationFailedStopHost, failure)
+	var typed *DeserializationError
+	require.ErrorAs(t, err, &typed)
+	assert.Equal(t, "orders", typed.Topic)
+	assert.Equal(t, int32(2), typed.Partition)
+	assert.Equal(t, kafka.Offset(41), typed.Offset)
+	assert.EqualError(t, typed.Unwrap(), "payload inválido")
+
+	assert.NoError(t, applyDeserializationStrategy(enums.OnDeserializationIgnoreMessage, failure))
+	assert.ErrorAs(t, applyDeserializationStrategy(enums.DeserializationStrategy(99), failure), &typed, "Estratégias desconhecidas deveriam encerrar o consumo")
+
+	assert.ErrorAs(t, applyDeserializationStrategy(enums.OnDeserializationFailedInvokeCallback, failure), &typed, "Sem callback o consumo deveria ser encerrado")
+
+	var received *kafka.Message
+	failure = newFailure(t, nil, ConsumeOptions{
+		DeserializationFailureCallback: func(record *kafka.Message, err error) error {
+			received = record
+			assert.ErrorAs(t, err, &typed)
+			return nil
+		},
+	})
+	assert.NoError(t, applyDeserializationStrategy(enums.OnDeserializationFailedInvokeCallback, failure))
+	assert.Same(t, failure.record, received)
+
+	failure.sub.options.DeserializationFailureCallback = func(*kafka.Message, error) error {
+		return errors.New("parar")
+	}
+	assert.EqualError(t, applyDeserializationStrategy(enums.OnDeserializationFailedInvokeCallback, failure), "parar")
+}
+
+// Teste do encaminhamento de mensagens indecodificáveis à dead-letter com um cluster simulado
+// O registro original é publicado em "<tópico>.dlq" com os cabeçalhos de diagnóstico.
+func TestDeserializationStrategySendsToDeadLetter(t *testing.T) {
+	cluster, err := kafka.NewMockCluster(1)
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	producer, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": cluster.BootstrapServers()})
+	require.NoError(t, err)
+	defer producer.Close()
+
+	failure := newFailure(t, producer, ConsumeOptions{})
+	require.NoError(t, applyDeserializationStrategy(enums.OnDeserializationFailedSendToDeadLetter, failure))
+
+	consumer, err := kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers": cluster.BootstrapServers(),
+		"group.id":          "dlq-test",
+		"auto.offset.reset": "earliest",
+	})
+	require.NoError(t, err)
+	defer consumer.Close()
+	require.NoError(t, consumer.Subscribe("orders.dlq", nil))
+
+	forwarded, err := consumer.ReadMessage(10 * time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("pedido-1"), forwarded.Key)
+	assert.Equal(t, []byte("{inválido"), forwarded.Value)
+
+	headers := make(map[string]string, len(forwarded.Headers))
+	for _, header := range forwarded.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+	assert.Equal(t, "api", headers["source"], "Os cabeçalhos originais deveriam ser preservados")
+	assert.Equal(t, "orders", headers[DeadLetterHeaderTopic])
+	assert.Equal(t, "2", headers[DeadLetterHeaderPartition])
+	assert.Equal(t, "41", headers[DeadLetterHeaderOffset])
+	assert.Equal(t, "0", headers[DeadLetterHeaderAttempts])
+	assert.Contains(t, headers[DeadLetterHeaderError], "payload inválido")
+}
+
+// Teste da espera limitada pela confirmação da dead-letter
+// Sem relatório de entrega, o encaminhamento falha no prazo ou no encerramento do consumidor, sem bloquear o consumo.
+func TestForwardToDeadLetterIsBounded(t *testing.T) {
+	producer, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": "127.0.0.1:1", "message.timeout.ms": 600000})
+	require.NoError(t, err)
+	defer producer.Close()
+
+	topic := "orders"
+	record := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic}, Value: []byte("pedido")}
+
+	started := time.Now()
+	target := forwarder{ctx: context.Background(), producer: producer, timeout: 100 * time.Millisecond}
+	err = forwardToDeadLetter(target, "orders.dlq", record, errors.New("falha"), 1)
+	assert.ErrorContains(t, err, "não confirmada")
+	assert.Less(t, time.Since(started), deliveryReportMargin+time.Second, "A espera deveria respeitar o message.timeout.ms")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	target = forwarder{ctx: ctx, producer: producer, timeout: time.Minute}
+	err = forwardToDeadLetter(target, "orders.dlq", record, errors.New("falha"), 1)
+	assert.ErrorIs(t, err, context.Canceled, "O encerramento do consumidor deveria interromper a espera")
+}
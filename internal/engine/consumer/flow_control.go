@@ -0,0 +1,186 @@
+package engine
+
+import (
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// BackpressureOptions define os limites de mensagens em processamento que pausam e retomam o consumo.
+// Quando as mensagens em processamento atingem HighWatermark todas as partições são pausadas, sem
+// deixar o grupo; o consumo é retomado quando elas caem para LowWatermark.
+type BackpressureOptions struct {
+	// HighWatermark é o número de mensagens em processamento que pausa o consumo (0 = padrão do modo)
+	HighWatermark int
+	// LowWatermark é o número de mensagens em processamento que retoma o consumo (0 = metade do HighWatermark)
+	LowWatermark int
+}
+
+// flowControl mantém as partições pausadas de uma assinatura pela aplicação ou por backpressure.
+// Executa apenas na goroutine de consumo; pausas de retentativa são controladas por delayedPartitions.
+type flowControl struct {
+	pausedAll    bool
+	userPaused   map[partitionKey]bool
+	backpressure bool
+	high         int
+	low          int
+	paused       map[partitionKey]kafka.TopicPartition
+	logger       logger.ILogger
+}
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// newFlowControl cria o controle de fluxo sem backpressure
+func newFlowControl(log logger.ILogger) *flowControl {
+	return &flowControl{
+		userPaused: make(map[partitionKey]bool),
+		paused:     make(map[partitionKey]kafka.TopicPartition),
+		logger:     log,
+	}
+}
+
+// ==========================================================================
+// Métodos
+// ==========================================================================
+
+// withDefaults retorna os limites com os valores padrão aplicados para o limite superior informado
+func (o BackpressureOptions) withDefaults(defaultHigh int) BackpressureOptions {
+	if o.HighWatermark <= 0 {
+		o.HighWatermark = defaultHigh
+	}
+	if o.LowWatermark <= 0 || o.LowWatermark >= o.HighWatermark {
+		o.LowWatermark = o.HighWatermark / 2
+	}
+	return o
+}
+
+// enableBackpressure habilita a pausa automática pelos limites informados
+func (f *flowControl) enableBackpressure(options BackpressureOptions) {
+	f.high = options.HighWatermark
+	f.low = options.LowWatermark
+}
+
+// apply executa uma solicitação de pausa ou retomada da aplicação.
+// Sem partições, a solicitação vale para todas as partições atribuídas, inclusive as atribuídas depois.
+func (f *flowControl) apply(client *kafka.Consumer, pause bool, partitions []kafka.TopicPartition, held func(partitionKey) bool) error {
+	switch {
+	case pause && len(partitions) == 0:
+		f.pausedAll = true
+	case pause:
+		for _, tp := range partitions {
+			f.userPaused[keyOf(tp)] = true
+		}
+	case len(partitions) == 0:
+		f.pausedAll = false
+		f.userPaused = make(map[partitionKey]bool)
+	default:
+		for _, tp := range partitions {
+			delete(f.userPaused, keyOf(tp))
+		}
+	}
+
+	assignment, err := client.Assignment()
+	if err != nil {
+		return err
+	}
+	return f.sync(client, assignment, held)
+}
+
+// pressure atualiza o backpressure com o número de mensagens em processamento,
+// pausando ao atingir o limite superior e retomando ao chegar ao limite inferior
+func (f *flowControl) pressure(client *kafka.Consumer, inFlight int, held func(partitionKey) bool) {
+	if f.high <= 0 {
+		return
+	}
+
+	switch {
+	case !f.backpressure && inFlight >= f.high:
+		f.backpressure = true
+		f.logger.Warn("Backpressure: pausing consumption", "inFlight", inFlight, "highWatermark", f.high)
+	case f.backpressure && inFlight <= f.low:
+		f.backpressure = false
+		f.logger.Info("Backpressure: resuming consumption", "inFlight", inFlight, "lowWatermark", f.low)
+	default:
+		return
+	}
+
+	assignment, err := client.Assignment()
+	if err == nil {
+		err = f.sync(client, assignment, held)
+	}
+	if err != nil {
+		f.logger.Error("Error on apply backpressure", logger.FieldError, err)
+	}
+}
+
+// assigned pausa as partições recém-atribuídas que devem permanecer pausadas
+func (f *flowControl) assigned(client *kafka.Consumer, partitions []kafka.TopicPartition) {
+	if err := f.sync(client, partitions, nil); err != nil {
+		f.logger.Error("Error on pause assigned partitions", "partitions", partitionsText(partitions), logger.FieldError, err)
+	}
+}
+
+// revoked descarta o estado de pausa das partições revogadas.
+// As pausas solicitadas para partições específicas são mantidas caso voltem a ser atribuídas.
+func (f *flowControl) revoked(partitions []kafka.TopicPartition) {
+	for _, tp := range partitions {
+		delete(f.paused, keyOf(tp))
+	}
+}
+
+// blocked indica se a partição deve permanecer pausada pela aplicação ou por backpressure
+func (f *flowControl) blocked(key partitionKey) bool {
+	return f.pausedAll || f.backpressure || f.userPaused[key]
+}
+
+// sync pausa e retoma as partições informadas conforme o estado desejado.
+// Partições retidas por outro mecanismo (held) não são retomadas.
+func (f *flowControl) sync(client *kafka.Consumer, partitions []kafka.TopicPartition, held func(partitionKey) bool) error {
+	var pause, resume []kafka.TopicPartition
+	for _, tp := range partitions {
+		key := keyOf(tp)
+		_, isPaused := f.paused[key]
+		switch wanted := f.blocked(key); {
+		case wanted && !isPaused:
+			pause = append(pause, tp)
+			f.paused[key] = tp
+		case !wanted && isPaused:
+			delete(f.paused, key)
+			if held == nil || !held(key) {
+				resume = append(resume, tp)
+			}
+		}
+	}
+
+	if len(pause) > 0 {
+		if err := client.Pause(pause); err != nil {
+			return err
+		}
+		f.logger.Info("Partitions paused", "partitions", partitionsText(pause))
+	}
+	if len(resume) > 0 {
+		if err := client.Resume(resume); err != nil {
+			return err
+		}
+		f.logger.Info("Partitions resumed", "partitions", partitionsText(resume))
+	}
+	return nil
+}
+
+// ==========================================================================
+// Funções
+// ==========================================================================
+
+// keyOf retorna a chave da partição de um TopicPartition
+func keyOf(tp kafka.TopicPartition) partitionKey {
+	topic := ""
+	if tp.Topic != nil {
+		topic = *tp.Topic
+	}
+	return partitionKey{topic: topic, partition: tp.Partition}
+}
@@ -12,5 +12,20 @@ import (
 // IKafkaConsumer define a interface pública para consumo de mensagens Kafka
 type IKafkaConsumer[TData any] interface {
 	// Consume inicia o consumo de mensagens de um tópico Kafka
-	Consume(topic string, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, handler func(message message.Message[TData]) error) error
+	Consume(topic string, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, options ConsumeOptions, handler func(message message.Message[TData]) error) error
+
+	// ConsumeWithContext inicia o consumo sequencial com um handler que recebe um contexto por mensagem, com prazo e metadados
+	ConsumeWithContext(topic string, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, options ConsumeOptions, handler ContextHandler[TData]) error
+
+	// ConsumeWithOutcome inicia o consumo sequencial com um handler que decide o destino de cada mensagem
+	ConsumeWithOutcome(topic string, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, options ConsumeOptions, handler OutcomeHandler[TData]) error
+
+	// ConsumeInParallel inicia o consumo de mensagens de um tópico Kafka distribuindo-as entre workers paralelos
+	ConsumeInParallel(topic string, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, parallelism ParallelOptions, options ConsumeOptions, handler func(message message.Message[TData]) error) error
+
+	// ConsumeBatch inicia o consumo de mensagens de um tópico Kafka entregando-as ao handler em lotes
+	ConsumeBatch(topic string, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, batchOptions BatchOptions, options ConsumeOptions, handler func(messages []message.Message[TData]) error) error
+
+	// ConsumeTransactionally inicia o consumo transacional, confirmando as publicações do handler e o offset atomicamente
+	ConsumeTransactionally(topic string, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, options ConsumeOptions, handler TransactionalHandler[TData]) error
 }
@@ -5,18 +5,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
-	"os/signal"
+	"maps"
 	"reflect"
+	"slices"
 	"strings"
-	"syscall"
+	"time"
 
 	internalEnums "github.com/Dieg657/kafka-toolkit-lib/internal/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/internal/common/logging"
 	"github.com/Dieg657/kafka-toolkit-lib/internal/common/setup"
 	"github.com/Dieg657/kafka-toolkit-lib/internal/engine/adapter"
+	"github.com/Dieg657/kafka-toolkit-lib/internal/engine/middleware"
 	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/constants"
 	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
 	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/ioc"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
 	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"github.com/google/uuid"
@@ -30,29 +33,32 @@ import (
 // Gerencia a conexão com o Kafka e deserialização de mensagens.
 type kafkaConsumer[TData any] struct {
 	ctx              context.Context
+	consumerSetup    setup.IKafkaConsumerSetup
 	client           *kafka.Consumer
-	priority         internalEnums.ConsumerOrderPriority
+	producer         *kafka.Producer
+	deliveryTimeout  time.Duration            // message.timeout.ms do produtor usado nos encaminhamentos
+	commitMode       internalEnums.CommitMode // Modo de commit da assinatura em execução
+	standalone       bool                     // Assinatura com partições atribuídas manualmente, sem grupo
+	maxPollInterval  time.Duration            // max.poll.interval.ms da assinatura em execução
 	registry         setup.ISchemaRegistrySetup
 	deserializerMap  map[enums.Deserialization]func(topic string, payload []byte, data *TData) error
 	protobufAdapter  *adapter.ProtobufAdapter
-	consumerPriority internalEnums.ConsumerOrderPriority
+	logger           logger.ILogger
+	consumerPriority internalEnums.ConsumerOrderPriority // Prioridade padrão do ambiente
 }
 
-var (
-	deserializationStrategyMap = map[enums.DeserializationStrategy]func(err error){
-		enums.OnDeserializationFailedStopHost: func(err error) {
-			if err != nil {
-				fmt.Printf("Error on deserialize message: %s\n", err)
-				panic(err)
-			}
-		},
-		enums.OnDeserializationIgnoreMessage: func(err error) {
-			if err != nil {
-				fmt.Printf("Error on deserialize message: %s\n", err)
-			}
-		},
-	}
-)
+// Mapa de conversão dos tipos de timestamp do Kafka para o modelo de mensagem
+var timestampTypeMap = map[kafka.TimestampType]message.TimestampType{
+	kafka.TimestampNotAvailable:  message.TimestampNotAvailable,
+	kafka.TimestampCreateTime:    message.TimestampCreateTime,
+	kafka.TimestampLogAppendTime: message.TimestampLogAppendTime,
+}
+
+// ErrConsumerRunning indica que a instância do consumidor já está atendendo uma assinatura
+var ErrConsumerRunning = errors.New("consumidor já está em execução")
+
+// standaloneGroupPrefix prefixa o grupo efêmero das assinaturas com partições atribuídas manualmente
+const standaloneGroupPrefix = "kafka-toolkit-standalone-"
 
 // ==========================================================================
 // Construtores
@@ -75,10 +81,14 @@ func NewKafkaConsumer[TData any](ctx context.Context) (IKafkaConsumer[TData], er
 	consumerSetup, schemaRegistry := container.GetConsumer()
 	consumer := &kafkaConsumer[TData]{}
 	consumer.ctx = ctx
-	consumer.client = consumerSetup.GetKafkaConsumer()
-	consumer.priority = container.GetConsumerPriority()
+	consumer.consumerSetup = consumerSetup // O cliente Kafka é criado por assinatura, em open
+	if producerSetup, _ := container.GetProducer(); producerSetup != nil {
+		consumer.producer = producerSetup.GetKafkaProducer() // Usado para encaminhar mensagens à dead-letter
+		consumer.deliveryTimeout = producerSetup.DeliveryTimeout()
+	}
 	consumer.registry = schemaRegistry
 	consumer.protobufAdapter = adapter.NewProtobufAdapter() // Inicializa o adaptador protobuf
+	consumer.logger = container.GetLogger()
 
 	// Obtém a prioridade do consumidor
 	consumer.consumerPriority = container.GetConsumerPriority()
@@ -97,78 +107,751 @@ func NewKafkaConsumer[TData any](ctx context.Context) (IKafkaConsumer[TData], er
 // Deserializa as mensagens com o formato especificado e chama o handler para cada uma delas.
 //
 // Parâmetros:
-//   - parallelWorkers: Número de workers paralelos para consumir mensagens
-//   - batchSize: Tamanho do lote de mensagens a ser consumido
 //   - topic: Nome do tópico Kafka para consumo
 //   - deserialization: Formato de deserialização a ser usado
 //   - strategy: Estratégia de tratamento de erro de deserialização
+//   - options: Configurações opcionais da assinatura (retentativas, dead-letter)
+//   - handler: Função a ser chamada para cada mensagem consumida
+//
+// Retorno:
+//   - error: Erro caso ocorra falha no consumo, incluindo *DeserializationError quando a estratégia encerra o consumo
+func (c *kafkaConsumer[TData]) Consume(topic string, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, options ConsumeOptions, handler func(message message.Message[TData]) error) error {
+	return c.consume(topic, deserialization, strategy, options, handlerProcessor(handler))
+}
+
+// ConsumeInParallel inicia o consumo de mensagens de um tópico Kafka distribuindo-as entre workers paralelos.
+// Mensagens com a mesma chave de ordenação são processadas sempre pelo mesmo worker e na ordem de leitura.
+// Os offsets só são confirmados até o maior offset contíguo já processado em cada partição. Quando uma
+// mensagem não pode ser tratada nem encaminhada, a partição é reposicionada nela e as mensagens posteriores
+// já despachadas voltam a ser consumidas. O poll nunca aguarda um worker: quando a fila do worker de uma
+// mensagem está cheia, a partição é pausada brevemente e a mensagem volta a ser lida.
+//
+// Parâmetros:
+//   - topic: Nome do tópico Kafka para consumo
+//   - deserialization: Formato de deserialização a ser usado
+//   - strategy: Estratégia de tratamento de erro de deserialização
+//   - parallelism: Número de workers, tamanho da fila por worker e ordenação
+//   - options: Configurações opcionais da assinatura (retentativas, dead-letter)
 //   - handler: Função a ser chamada para cada mensagem consumida
 //
 // Retorno:
 //   - error: Erro caso ocorra falha no consumo
-func (c *kafkaConsumer[TData]) Consume(topic string, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, handler func(message message.Message[TData]) error) error {
-	sigchan := make(chan os.Signal, 1)
-	signal.Notify(sigchan, syscall.SIGINT, syscall.SIGTERM)
+func (c *kafkaConsumer[TData]) ConsumeInParallel(topic string, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, parallelism ParallelOptions, options ConsumeOptions, handler func(message message.Message[TData]) error) (consumeErr error) {
+	if parallelism.Workers <= 0 {
+		return errors.New("o número de workers paralelos deve ser maior que zero")
+	}
 
-	err := c.client.Subscribe(topic, rebalanceCallback)
+	if err := options.Controller.attach(); err != nil {
+		return err
+	}
+	defer func() { options.Controller.finish(consumeErr) }()
+	stop := options.Controller.stopping()
+	commands := options.Controller.control()
 
+	sub, err := newSubscription(topic, options, c.logger)
 	if err != nil {
 		return err
 	}
 
+	// Os níveis de retentativa são consumidos sequencialmente, cada um pelo seu próprio consumidor
+	retries := c.startRetries(sub, deserialization, strategy, handlerProcessor(handler))
+	defer retries.close()
+
+	if err := c.open(sub); err != nil {
+		return err
+	}
+
+	err = c.subscribe(sub)
+	if err != nil {
+		c.close()
+		return err
+	}
+
+	tracker := newOffsetTracker()
+	// Na revogação aguarda os workers terminarem as mensagens das partições revogadas
+	sub.onRevoke = tracker.release
+	dispatcher := newParallelDispatcher(parallelism)
+	// Pausa a busca quando as mensagens em processamento atingem o limite superior, limitando a memória
+	sub.flow.enableBackpressure(options.Backpressure.withDefaults(parallelism.Workers * max(parallelism.QueueSize, 1)))
+
 	run := true
 
 	for run {
 		select {
-		case sig := <-sigchan:
-			fmt.Printf("Terminating consumer signal %v cought!\n", sig)
+		case <-stop:
+			c.logger.Info("Terminating consumer: close requested", logger.FieldTopic, topic)
 			run = false
 		case <-c.ctx.Done():
-			fmt.Printf("Terminating consumer context done!\n")
+			c.logger.Info("Terminating consumer: context done", logger.FieldTopic, topic)
 			run = false
+		case err := <-retries.failed():
+			c.logger.Error("Terminating consumer: retry consumer failed", logger.FieldTopic, topic, logger.FieldError, err)
+			consumeErr = err
+			run = false
+		case command := <-commands:
+			sub.command(c.client, command)
 		default:
+			if sub.finished(c.client) {
+				c.logger.Info("Terminating consumer: end offsets reached", logger.FieldTopic, topic)
+				run = false
+				continue
+			}
+
+			c.commitTracked(tracker)
+			for _, tp := range tracker.rewinds() {
+				c.rewind(tp)
+			}
+			c.commitRequested(sub)
+			sub.pressure(c.client, tracker.pending())
+			sub.resumeDue(c.client)
+
 			ev := c.client.Poll(100)
 			if ev == nil {
 				continue
 			}
 			switch e := ev.(type) {
 			case *kafka.Message:
-				baseMessage := message.Message[TData]{
-					Metadata: make(map[string][]byte, len(e.Headers)),
+				if sub.hold(c.client, e) || sub.beyond(c.client, e) {
+					continue
+				}
+				if tracker.rewinding(e.TopicPartition) {
+					// Lida antes do reposicionamento da partição: será consumida novamente
+					continue
 				}
-				c.fillHeader(&baseMessage, e.Headers)
+				if dispatcher.saturated(e) && sub.saturate(c.client, e) {
+					// A fila do worker está cheia: a mensagem volta a ser lida sem bloquear o poll
+					continue
+				}
+
+				baseMessage, decoded, err := c.prepareMessage(sub, e, deserialization, strategy)
+				if err != nil {
+					consumeErr = err
+					run = false
+					continue
+				}
+
+				if !tracker.track(e.TopicPartition) {
+					// Uma falha na partição ocorreu durante a preparação: a mensagem será consumida novamente
+					continue
+				}
+				// A partição termina ao despachar a última mensagem; o encerramento aguarda os workers
+				sub.processed(c.client, e.TopicPartition)
+				if !decoded {
+					// Mensagem descartada pela estratégia: já pode ser confirmada
+					tracker.complete(e.TopicPartition)
+					continue
+				}
+
+				dispatcher.dispatch(e, func() {
+					if !tracker.start(e.TopicPartition) {
+						// Descartada pela falha de uma mensagem anterior da partição: será despachada novamente
+						return
+					}
+					if err := c.handleMessage(sub, e, baseMessage, handler); err != nil {
+						// A mensagem não pôde ser tratada nem encaminhada: a partição volta a ser consumida a partir dela
+						c.logger.Error("Error on handle message", recordFields(e, logger.FieldError, err)...)
+						tracker.fail(e.TopicPartition)
+						return
+					}
+					tracker.complete(e.TopicPartition)
+				})
+			case kafka.PartitionEOF:
+				sub.endOfPartition(c.client, e)
+			case kafka.Error:
+				c.logger.Error("Kafka client error", logger.FieldTopic, topic, "code", e.Code().String(), logger.FieldError, e)
+			default:
+				c.logger.Debug("Ignored event", logger.FieldTopic, topic, "event", e.String())
+			}
+		}
+	}
 
-				err = c.deserializeValue(e, deserialization, &baseMessage.Data)
-				deserializationStrategyMap[strategy](err)
+	// Aguarda os workers concluírem as mensagens já despachadas antes do commit final
+	dispatcher.close()
+	c.shutdown(sub, tracker.committable())
+
+	return consumeErr
+}
 
-				err = handler(baseMessage)
+// ConsumeBatch inicia o consumo de mensagens de um tópico Kafka entregando-as ao handler em lotes.
+// O lote é entregue ao atingir o tamanho máximo, o limite de bytes ou o tempo máximo de espera.
+// Os offsets do lote só são confirmados quando o handler retorna nil; com um BatchFailure, cada
+// partição avança apenas até a primeira mensagem falha, que volta a ser consumida.
+//
+// Parâmetros:
+//   - topic: Nome do tópico Kafka para consumo
+//   - deserialization: Formato de deserialização a ser usado
+//   - strategy: Estratégia de tratamento de erro de deserialização
+//   - batchOptions: Limites de tamanho, bytes e tempo de espera do lote
+//   - options: Configurações opcionais da assinatura (dead-letter e callback de deserialização)
+//   - handler: Função a ser chamada para cada lote consumido
+//
+// Retorno:
+//   - error: Erro caso ocorra falha no consumo, incluindo *DeserializationError quando a estratégia encerra o consumo
+func (c *kafkaConsumer[TData]) ConsumeBatch(topic string, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, batchOptions BatchOptions, options ConsumeOptions, handler func(messages []message.Message[TData]) error) (consumeErr error) {
+	batchOptions = batchOptions.withDefaults()
+
+	if err := options.Controller.attach(); err != nil {
+		return err
+	}
+	defer func() { options.Controller.finish(consumeErr) }()
+	stop := options.Controller.stopping()
+	commands := options.Controller.control()
+
+	// Os tópicos de retentativa não se aplicam ao consumo em lotes
+	options.RetryTopicDelays = nil
+	sub, err := newSubscription(topic, options, c.logger)
+	if err != nil {
+		return err
+	}
+
+	if err := c.open(sub); err != nil {
+		return err
+	}
+
+	err = c.subscribe(sub)
+	if err != nil {
+		c.close()
+		return err
+	}
+
+	batch := &messageBatch{}
+	messages := make([]message.Message[TData], 0, batchOptions.MaxSize)
+
+	flush := func() []kafka.TopicPartition {
+		commits := c.handleBatch(sub, batch, messages, handler)
+		batch.reset()
+		messages = messages[:0]
+		return commits
+	}
+	// Na revogação o lote em formação é entregue e confirmado antes de as partições mudarem de dono
+	sub.onRevoke = func([]kafka.TopicPartition) []kafka.TopicPartition {
+		return flush()
+	}
+
+	run := true
+
+	for run {
+		select {
+		case <-stop:
+			c.logger.Info("Terminating consumer: close requested", logger.FieldTopic, topic)
+			run = false
+		case <-c.ctx.Done():
+			c.logger.Info("Terminating consumer: context done", logger.FieldTopic, topic)
+			run = false
+		case command := <-commands:
+			sub.command(c.client, command)
+		default:
+			if sub.finished(c.client) {
+				// O lote em formação contém as últimas mensagens das partições e é entregue antes do encerramento
+				flush()
+				c.logger.Info("Terminating consumer: end offsets reached", logger.FieldTopic, topic)
+				run = false
+				continue
+			}
+
+			c.commitRequested(sub)
+
+			ev := c.client.Poll(100)
+			switch e := ev.(type) {
+			case nil:
+			case *kafka.Message:
+				if sub.beyond(c.client, e) {
+					continue
+				}
+
+				baseMessage, decoded, err := c.prepareMessage(sub, e, deserialization, strategy)
 				if err != nil {
-					fmt.Println("Error on handle message")
+					consumeErr = err
+					run = false
+					continue
 				}
 
-				if c.priority == internalEnums.CONSUMER_ORDER_PRIORITY_HIGH_PERFORMANCE || c.priority == internalEnums.CONSUMER_ORDER_PRIORITY_RISKY {
+				if !decoded {
+					// Mensagem descartada pela estratégia: confirmada junto com o próximo lote
+					batch.skip(e)
+					sub.processed(c.client, e.TopicPartition)
 					continue
 				}
 
-				err = commitMessage(c.client, e.TopicPartition)
+				key, seen, err := c.duplicate(sub, e)
 				if err != nil {
-					fmt.Println("Error on commit message")
+					// Sem o store não é possível saber se a mensagem já foi tratada: ela volta a ser consumida
+					c.rewind(e.TopicPartition)
+					continue
+				}
+				if seen {
+					// Duplicata: confirmada junto com o próximo lote sem ser entregue ao handler
+					batch.skip(e)
+					sub.processed(c.client, e.TopicPartition)
+					continue
 				}
+
+				messages = append(messages, baseMessage)
+				batch.add(e, key, batchOptions.MaxWait)
+				sub.processed(c.client, e.TopicPartition)
+				if batch.full(batchOptions) {
+					flush()
+				}
+			case kafka.PartitionEOF:
+				sub.endOfPartition(c.client, e)
 			case kafka.Error:
-				fmt.Fprintf(os.Stderr, "%% Error: %v: %v\n", e.Code(), e)
+				c.logger.Error("Kafka client error", logger.FieldTopic, topic, "code", e.Code().String(), logger.FieldError, e)
 			default:
-				fmt.Printf("Ignored %v\n", e)
+				c.logger.Debug("Ignored event", logger.FieldTopic, topic, "event", e.String())
+			}
+
+			if batch.expired() {
+				flush()
 			}
 		}
 	}
 
-	return nil
+	// Mensagens de um lote ainda não entregue não foram confirmadas e serão consumidas novamente.
+	// A revogação disparada pelo fechamento do cliente não deve entregar o lote em formação.
+	sub.onRevoke = nil
+	c.shutdown(sub, nil)
+	return consumeErr
 }
 
 // ==========================================================================
 // Métodos Privados
 // ==========================================================================
 
+// consume executa o consumo sequencial de Consume, ConsumeWithContext e ConsumeWithOutcome.
+// Cada mensagem deserializada é entregue ao processador criado por processor, cuja disposição define se o
+// offset é confirmado, se a mensagem é apenas ignorada ou se voltará a ser consumida. Com tópicos de
+// retentativa, cada nível é consumido em paralelo por um consumidor próprio, encerrado junto com a assinatura.
+func (c *kafkaConsumer[TData]) consume(topic string, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, options ConsumeOptions, processor processorFactory[TData]) (consumeErr error) {
+	if err := options.Controller.attach(); err != nil {
+		return err
+	}
+	defer func() { options.Controller.finish(consumeErr) }()
+
+	sub, err := newSubscription(topic, options, c.logger)
+	if err != nil {
+		return err
+	}
+
+	retries := c.startRetries(sub, deserialization, strategy, processor)
+	defer retries.close()
+
+	return c.run(sub, deserialization, strategy, processor(c), options.Controller.stopping(), options.Controller.control(), retries.failed())
+}
+
+// run executa o laço de consumo sequencial da assinatura até o pedido de encerramento (stop), o fim do
+// contexto, o fim do consumo limitado ou a falha de um consumidor de retentativa da assinatura (failed)
+func (c *kafkaConsumer[TData]) run(sub *subscription, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, process messageProcessor[TData], stop <-chan struct{}, commands <-chan controlCommand, failed <-chan error) (consumeErr error) {
+	topic := sub.topic
+	if err := c.open(sub); err != nil {
+		return err
+	}
+
+	if err := c.subscribe(sub); err != nil {
+		c.close()
+		return err
+	}
+
+	// Próximo offset de cada partição após a última mensagem concluída, confirmado na revogação e no encerramento
+	processed := make(map[partitionKey]kafka.TopicPartition)
+	sub.onRevoke = func(partitions []kafka.TopicPartition) []kafka.TopicPartition {
+		return releaseProcessed(processed, partitions)
+	}
+	run := true
+
+	for run {
+		select {
+		case <-stop:
+			c.logger.Info("Terminating consumer: close requested", logger.FieldTopic, topic)
+			run = false
+		case <-c.ctx.Done():
+			c.logger.Info("Terminating consumer: context done", logger.FieldTopic, topic)
+			run = false
+		case err := <-failed:
+			c.logger.Error("Terminating consumer: retry consumer failed", logger.FieldTopic, topic, logger.FieldError, err)
+			consumeErr = err
+			run = false
+		case command := <-commands:
+			sub.command(c.client, command)
+		default:
+			if sub.finished(c.client) {
+				c.logger.Info("Terminating consumer: end offsets reached", logger.FieldTopic, topic)
+				run = false
+				continue
+			}
+
+			c.commitRequested(sub)
+			sub.resumeDue(c.client)
+
+			ev := c.client.Poll(100)
+			if ev == nil {
+				continue
+			}
+			switch e := ev.(type) {
+			case *kafka.Message:
+				if sub.hold(c.client, e) || sub.beyond(c.client, e) {
+					continue
+				}
+
+				baseMessage, decoded, err := c.prepareMessage(sub, e, deserialization, strategy)
+				if err != nil {
+					consumeErr = err
+					run = false
+					continue
+				}
+
+				result := dispositionHandled
+				if decoded {
+					result = process(sub, e, baseMessage)
+				}
+
+				switch result {
+				case dispositionRedelivered:
+					continue
+				case dispositionHandled:
+					markProcessed(processed, e.TopicPartition)
+					c.handled(e.TopicPartition)
+				}
+				sub.processed(c.client, e.TopicPartition)
+			case kafka.PartitionEOF:
+				sub.endOfPartition(c.client, e)
+			case kafka.Error:
+				c.logger.Error("Kafka client error", logger.FieldTopic, topic, "code", e.Code().String(), logger.FieldError, e)
+			default:
+				c.logger.Debug("Ignored event", logger.FieldTopic, topic, "event", e.String())
+			}
+		}
+	}
+
+	c.shutdown(sub, processedOffsets(processed))
+	return consumeErr
+}
+
+// handlerProcessor retorna a fábrica do processador de Consume e ConsumeInParallel: o handler é executado com
+// a política de falhas da assinatura e, se a mensagem não puder ser tratada nem encaminhada, ela volta a ser consumida
+func handlerProcessor[TData any](handler func(message message.Message[TData]) error) processorFactory[TData] {
+	return func(c *kafkaConsumer[TData]) messageProcessor[TData] {
+		return c.sequential(func(sub *subscription, record *kafka.Message, baseMessage message.Message[TData]) error {
+			return c.handleMessage(sub, record, baseMessage, handler)
+		})
+	}
+}
+
+// sequential adapta o tratamento de uma mensagem ao laço de consumo sequencial: quando a mensagem não
+// pode ser tratada nem encaminhada, a partição é reposicionada para que ela volte a ser consumida
+func (c *kafkaConsumer[TData]) sequential(handle func(sub *subscription, record *kafka.Message, baseMessage message.Message[TData]) error) messageProcessor[TData] {
+	return func(sub *subscription, record *kafka.Message, baseMessage message.Message[TData]) disposition {
+		if err := handle(sub, record, baseMessage); err != nil {
+			c.logger.Error("Error on handle message", recordFields(record, logger.FieldError, err)...)
+			c.rewind(record.TopicPartition)
+			return dispositionRedelivered
+		}
+		return dispositionHandled
+	}
+}
+
+// handleMessage executa o handler aplicando a política de falhas da assinatura.
+// Em caso de erro o handler é repetido conforme a RetryPolicy; esgotadas as tentativas,
+// a mensagem original segue para o próximo tópico de retentativa ou para a dead-letter.
+// Com deduplicação, mensagens já processadas são confirmadas sem chamar o handler.
+// Falhas com ErrHandlerAborted não são repetidas e seguem direto para o encaminhamento.
+// Retorna erro apenas quando a mensagem não pôde ser tratada nem encaminhada e não deve ser confirmada.
+func (c *kafkaConsumer[TData]) handleMessage(sub *subscription, record *kafka.Message, baseMessage message.Message[TData], handler func(message message.Message[TData]) error) error {
+	return c.handleWithin(sub, record, baseMessage, 0, func(_ time.Time, message message.Message[TData]) error {
+		return handler(message)
+	})
+}
+
+// handleWithin executa handleMessage com um prazo único para a mensagem, calculado ao receber a mensagem e
+// repassado a todas as tentativas. As retentativas param quando o prazo restante não comporta a espera e
+// uma nova tentativa com a duração da anterior, e a mensagem segue para o encaminhamento. Sem prazo (budget
+// zero) as tentativas seguem apenas a RetryPolicy.
+func (c *kafkaConsumer[TData]) handleWithin(sub *subscription, record *kafka.Message, baseMessage message.Message[TData], budget time.Duration, handler attemptHandler[TData]) error {
+	options := sub.options
+	attempts := 0
+
+	key, seen, err := c.duplicate(sub, record)
+	if err != nil || seen {
+		return err
+	}
+
+	var deadline time.Time
+	if budget > 0 {
+		deadline = time.Now().Add(budget)
+	}
+
+	for {
+		attempts++
+		started := time.Now()
+		err := c.intercept(sub, record, baseMessage, func(message message.Message[TData]) error {
+			return handler(deadline, message)
+		})
+		if err == nil {
+			c.remember(sub, record, key)
+			return nil
+		}
+
+		if c.ctx.Err() != nil {
+			// O consumidor está encerrando: a mensagem não é encaminhada e volta a ser consumida
+			return c.ctx.Err()
+		}
+
+		// O handler abandonado pelo watchdog ou pelo middleware Timeout pode continuar executando:
+		// repeti-lo em memória sobreporia duas execuções da mesma mensagem
+		if attempts > options.RetryPolicy.MaxRetries || errors.Is(err, ErrHandlerAborted) || errors.Is(err, middleware.ErrTimeout) {
+			return c.handleFailure(sub, record, err, attempts)
+		}
+
+		backoff := options.RetryPolicy.Backoff(attempts)
+		if !deadline.IsZero() && time.Until(deadline) <= backoff+time.Since(started) {
+			// O prazo restante não comporta outra tentativa: insistir excederia o max.poll.interval.ms
+			c.logger.Warn("Message deadline exhausted, giving up retries", recordFields(record, "attempt", attempts, "deadline", deadline, logger.FieldError, err)...)
+			return c.handleFailure(sub, record, err, attempts)
+		}
+
+		c.logger.Warn("Error on handle message, retrying", recordFields(record, "attempt", attempts, logger.FieldError, err)...)
+
+		select {
+		case <-c.ctx.Done():
+			return c.ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// intercept executa o handler dentro da cadeia de middlewares da assinatura.
+// Os cabeçalhos acrescentados ao registro pelos middlewares ficam disponíveis nos metadados da mensagem.
+// Cada tentativa recebe uma cópia do registro com os seus próprios cabeçalhos: as alterações dos
+// middlewares não se acumulam entre retentativas nem chegam ao registro encaminhado à retentativa ou dead-letter.
+func (c *kafkaConsumer[TData]) intercept(sub *subscription, record *kafka.Message, baseMessage message.Message[TData], handler func(message message.Message[TData]) error) error {
+	if len(sub.options.Middlewares) == 0 {
+		return handler(baseMessage)
+	}
+
+	chain := middleware.Chain(func(record *kafka.Message) error {
+		enriched := baseMessage
+		enriched.Metadata = maps.Clone(baseMessage.Metadata)
+		c.fillHeader(&enriched, record.Headers)
+		return handler(enriched)
+	}, sub.options.Middlewares...)
+
+	attempt := *record
+	attempt.Headers = slices.Clone(record.Headers)
+	return chain(&attempt)
+}
+
+// handleFailure trata uma mensagem que esgotou as tentativas de processamento.
+// Com tópicos de retentativa, a mensagem é republicada no próximo nível; após o último nível,
+// ou sem níveis configurados, segue para a dead-letter configurada ou, se ausente, "<tópico>.dlq",
+// como nas demais formas de encaminhamento à dead-letter.
+func (c *kafkaConsumer[TData]) handleFailure(sub *subscription, record *kafka.Message, cause error, attempts int) error {
+	deadLetterTopic := sub.deadLetterTopic(record)
+
+	if tiers := sub.tiersOf(record); tiers != nil {
+		level := tiers.level(record) + 1
+		if level <= len(tiers.topics) {
+			err := forwardToRetryTier(c.forwarder(), tiers, level, record, cause)
+			if err != nil {
+				return err
+			}
+			c.logger.Warn("Message forwarded to retry topic", recordFields(record, "retryTopic", tiers.topics[level-1], logger.FieldError, cause)...)
+			return nil
+		}
+		deadLetterTopic = tiers.deadLetterTopic
+		// Cada nível já executou a política de retentativa em memória completa
+		attempts += (level - 1) * (sub.options.RetryPolicy.MaxRetries + 1)
+	}
+
+	err := forwardToDeadLetter(c.forwarder(), deadLetterTopic, record, cause, attempts)
+	if err != nil {
+		return err
+	}
+
+	c.logger.Warn("Message forwarded to dead-letter topic", recordFields(record, "deadLetterTopic", deadLetterTopic, "attempts", attempts, logger.FieldError, cause)...)
+	return nil
+}
+
+// forwarder retorna o publicador dos encaminhamentos à retentativa e à dead-letter, com a espera pela
+// confirmação limitada ao message.timeout.ms do produtor e ao encerramento do consumidor
+func (c *kafkaConsumer[TData]) forwarder() forwarder {
+	return forwarder{ctx: c.ctx, producer: c.producer, timeout: c.deliveryTimeout}
+}
+
+// rewind reposiciona a partição no offset informado para que a mensagem seja consumida novamente
+func (c *kafkaConsumer[TData]) rewind(tp kafka.TopicPartition) {
+	if err := c.client.Seek(tp, 0); err != nil {
+		c.logger.Error("Error on seek partition", partitionFields(tp, logger.FieldError, err)...)
+	}
+}
+
+// handleBatch entrega o lote ao handler e registra, conforme o modo de commit, apenas as mensagens
+// processadas, retornando os próximos offsets de cada partição. Partições com falha são reposicionadas
+// na primeira mensagem falha para novo consumo.
+func (c *kafkaConsumer[TData]) handleBatch(sub *subscription, batch *messageBatch, messages []message.Message[TData], handler func(messages []message.Message[TData]) error) []kafka.TopicPartition {
+	failed := make(map[int]struct{})
+
+	var err error
+	if len(messages) > 0 {
+		err = handler(messages)
+	}
+	if err != nil {
+		c.logger.Error("Error on handle batch", "size", len(messages), logger.FieldError, err)
+
+		var partial *BatchFailure
+		if errors.As(err, &partial) {
+			for _, index := range partial.Failed {
+				failed[index] = struct{}{}
+			}
+		} else {
+			for index := range batch.records {
+				failed[index] = struct{}{}
+			}
+		}
+	}
+
+	// As mensagens tratadas são registradas no store mesmo quando voltam a ser consumidas por uma falha anterior da partição
+	for index, record := range batch.records {
+		if _, isFailed := failed[index]; !isFailed {
+			c.remember(sub, record, batch.keys[index])
+		}
+	}
+
+	commits, rewinds := batch.commitPositions(failed)
+	c.advanceOffsets(commits)
+
+	for _, tp := range rewinds {
+		c.rewind(tp)
+	}
+	return commits
+}
+
+// shutdown encerra a assinatura: confirma de forma síncrona os offsets tratados informados,
+// deixa o grupo de consumidores e fecha o cliente Kafka.
+func (c *kafkaConsumer[TData]) shutdown(sub *subscription, offsets []kafka.TopicPartition) {
+	c.commitFinal(c.client, sub, offsets, nil)
+	c.close()
+}
+
+// fork cria uma instância do consumidor com as mesmas dependências e sem cliente, para uma assinatura própria
+func (c *kafkaConsumer[TData]) fork() *kafkaConsumer[TData] {
+	fork := *c
+	fork.client = nil
+	return &fork
+}
+
+// close deixa o grupo de consumidores e fecha o cliente Kafka da assinatura
+func (c *kafkaConsumer[TData]) close() {
+	err := c.client.Close()
+	c.client = nil
+	if err != nil {
+		c.logger.Error("Error on close consumer", logger.FieldError, err)
+		return
+	}
+	c.logger.Info("Consumer closed")
+}
+
+// open cria o cliente Kafka exclusivo da assinatura, com seu próprio membro no grupo e laço de poll,
+// aplicando o grupo, o auto.offset.reset e a prioridade informados nas opções. Os consumidores dos tópicos
+// de retentativa usam um grupo próprio, derivado do grupo da assinatura com o sufixo do nível.
+// Com partições atribuídas manualmente e sem grupo informado, o cliente usa um grupo efêmero
+// exigido pelo librdkafka e nenhum offset é confirmado.
+// Uma instância do consumidor atende uma assinatura por vez.
+func (c *kafkaConsumer[TData]) open(sub *subscription) error {
+	if c.client != nil {
+		return ErrConsumerRunning
+	}
+
+	options := sub.options
+	c.commitMode = internalEnums.CommitMode(options.CommitMode)
+	overrides := setup.ConsumerOverrides{
+		GroupId:            options.GroupId,
+		GroupSuffix:        sub.groupSuffix,
+		AutoOffsetReset:    internalEnums.AutoOffsetReset(options.AutoOffsetReset),
+		Priority:           internalEnums.ConsumerOrderPriority(options.Priority),
+		AssignmentStrategy: string(options.AssignmentStrategy),
+		PartitionEOF:       options.Bounded,
+		ReadCommitted:      sub.readCommitted,
+		CommitMode:         c.commitMode,
+		CommitInterval:     options.CommitInterval,
+	}
+
+	c.standalone = len(options.Partitions) > 0 && options.GroupId == ""
+	if c.standalone {
+		// Sem grupo os offsets não são confirmados, nem pelo librdkafka
+		overrides.GroupId = standaloneGroupPrefix + uuid.NewString()
+		overrides.CommitMode = internalEnums.COMMIT_MODE_MANUAL
+	}
+
+	c.maxPollInterval = c.consumerSetup.MaxPollInterval(overrides)
+	client, err := c.consumerSetup.NewKafkaConsumer(overrides)
+	if err != nil {
+		return fmt.Errorf("falha ao criar consumidor Kafka: %w", err)
+	}
+	logging.ForwardClientLogs(c.logger, "consumer", client.Logs())
+
+	c.client = client
+	return nil
+}
+
+// subscribe assina os tópicos no grupo de consumidores ou, com partições informadas nas opções,
+// atribui-as diretamente ao cliente, sem rebalanceamento. Na atribuição manual a posição inicial,
+// a pausa e o hook OnAssigned são aplicados como em uma atribuição do grupo.
+func (c *kafkaConsumer[TData]) subscribe(sub *subscription) error {
+	if !sub.manual() {
+		return c.client.SubscribeTopics(sub.topics(), c.rebalanceCallback(sub))
+	}
+
+	partitions := sub.startAt(c.client, sub.partitions())
+	if err := c.client.Assign(partitions); err != nil {
+		return err
+	}
+	c.logger.Info("Partitions assigned manually", "count", len(partitions), "partitions", partitionsText(partitions), "standalone", c.standalone)
+	sub.assigned(c.client, partitions)
+
+	if hook := sub.options.Rebalance.OnAssigned; hook != nil {
+		hook(partitions)
+	}
+	return nil
+}
+
+// prepareMessage monta a mensagem tipada e aplica a estratégia de deserialização em caso de falha.
+// Retorna decoded=false quando a mensagem deve ser confirmada sem chamar o handler, e erro
+// quando a estratégia determina o encerramento do consumo.
+func (c *kafkaConsumer[TData]) prepareMessage(sub *subscription, e *kafka.Message, deserialization enums.Deserialization, strategy enums.DeserializationStrategy) (message.Message[TData], bool, error) {
+	baseMessage, err := c.buildMessage(e, sub.deserializationTopic(e), deserialization)
+	if err == nil {
+		return baseMessage, true, nil
+	}
+
+	failure := deserializationFailure{
+		forwarder: c.forwarder(),
+		sub:       sub,
+		record:    e,
+		err:       newDeserializationError(e, err),
+		logger:    c.logger,
+	}
+
+	return baseMessage, false, applyDeserializationStrategy(strategy, failure)
+}
+
+// buildMessage monta a mensagem tipada a partir da mensagem Kafka,
+// preenchendo os metadados e deserializando o payload com o subject do tópico informado.
+func (c *kafkaConsumer[TData]) buildMessage(e *kafka.Message, topic string, deserialization enums.Deserialization) (message.Message[TData], error) {
+	baseMessage := message.Message[TData]{
+		Metadata: make(map[string][]byte, len(e.Headers)),
+	}
+	c.fillRecord(&baseMessage, e)
+	c.fillHeader(&baseMessage, e.Headers)
+
+	err := c.deserializeValue(topic, e, deserialization, &baseMessage.Data)
+
+	return baseMessage, err
+}
+
+// commitTracked registra, conforme o modo de commit, os offsets contíguos já processados pelos workers
+func (c *kafkaConsumer[TData]) commitTracked(tracker *offsetTracker) {
+	c.advanceOffsets(tracker.committable())
+}
+
 // initializeDeserializers configura todos os deserializadores suportados pela biblioteca.
 // Centraliza a criação e registro dos deserializadores padrão.
 func (c *kafkaConsumer[TData]) initializeDeserializers() {
@@ -218,13 +901,24 @@ func (c *kafkaConsumer[TData]) initializeDeserializers() {
 
 // deserializeValue deserializa o payload de uma mensagem usando o deserializador apropriado.
 // Seleciona o deserializador com base no tipo de deserialização especificado.
-func (c *kafkaConsumer[TData]) deserializeValue(e *kafka.Message, deserialization enums.Deserialization, data *TData) error {
+func (c *kafkaConsumer[TData]) deserializeValue(topic string, e *kafka.Message, deserialization enums.Deserialization, data *TData) error {
 	if deserializerFunc, exists := c.deserializerMap[deserialization]; exists {
-		return deserializerFunc(*e.TopicPartition.Topic, e.Value, data)
+		return deserializerFunc(topic, e.Value, data)
 	}
 	return errors.New("invalid deserializer")
 }
 
+// fillRecord preenche as coordenadas, a chave e o timestamp da mensagem a partir do registro Kafka
+func (c *kafkaConsumer[TData]) fillRecord(message *message.Message[TData], e *kafka.Message) {
+	message.Key = e.Key
+	message.Topic = topicOf(e)
+	message.Partition = e.TopicPartition.Partition
+	message.Offset = int64(e.TopicPartition.Offset)
+	message.Timestamp = e.Timestamp
+	message.TimestampType = timestampTypeMap[e.TimestampType]
+	message.LeaderEpoch = e.TopicPartition.LeaderEpoch
+}
+
 // fillHeader preenche os metadados da mensagem com base nos cabeçalhos Kafka.
 // Extrai também o correlationId, se disponível.
 func (c *kafkaConsumer[TData]) fillHeader(message *message.Message[TData], headers []kafka.Header) {
@@ -243,43 +937,3 @@ func (c *kafkaConsumer[TData]) fillHeader(message *message.Message[TData], heade
 		message.CorrelationId = uuid.New()
 	}
 }
-
-// Callback do cliente Kafka que recebe eventos de atribuição/revogação de partições.
-// Gerencia atribuição e revogação de partições durante o rebalanceamento.
-func rebalanceCallback(c *kafka.Consumer, event kafka.Event) error {
-	switch ev := event.(type) {
-	case kafka.AssignedPartitions:
-		fmt.Printf("Rebalance Protocol: %s rebalance: %d new partition(s) assigned: %v\n",
-			c.GetRebalanceProtocol(), len(ev.Partitions), ev.Partitions)
-		err := c.Assign(ev.Partitions)
-		if err != nil {
-			return err
-		}
-	case kafka.RevokedPartitions:
-		fmt.Printf("Rebalance Protocol: %s rebalance: %d partition(s) revoked: %v\n",
-			c.GetRebalanceProtocol(), len(ev.Partitions), ev.Partitions)
-		if c.AssignmentLost() {
-			fmt.Fprintln(os.Stderr, "Assignment lost involuntarily, commit may fail")
-		}
-	default:
-		fmt.Fprintf(os.Stderr, "Rebalance Protocol: %s Unxpected event type: %v\n", c.GetRebalanceProtocol(), event)
-	}
-
-	return nil
-}
-
-// commitMessage realiza o commit do offset da mensagem no Kafka.
-// Faz commit a cada 10 mensagens para melhorar a performance.
-func commitMessage(c *kafka.Consumer, topicPartition kafka.TopicPartition) error {
-	if topicPartition.Offset%10 != 0 {
-		return nil
-	}
-
-	_, err := c.Commit()
-
-	if err != nil && err.(kafka.Error).Code() != kafka.ErrNoOffset {
-		return err
-	}
-
-	return nil
-}
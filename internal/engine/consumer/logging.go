@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Funções
+// ==========================================================================
+
+// recordFields retorna os campos estruturados de log de um registro Kafka:
+// tópico, partição, offset e, se presente no cabeçalho, o correlationId. Os args são anexados ao final.
+func recordFields(record *kafka.Message, args ...any) []any {
+	fields := partitionFields(record.TopicPartition)
+	for _, header := range record.Headers {
+		if strings.EqualFold(header.Key, logger.FieldCorrelationId) {
+			fields = append(fields, logger.FieldCorrelationId, string(header.Value))
+			break
+		}
+	}
+	return append(fields, args...)
+}
+
+// partitionFields retorna os campos estruturados de log de uma partição e offset
+func partitionFields(tp kafka.TopicPartition, args ...any) []any {
+	topic := ""
+	if tp.Topic != nil {
+		topic = *tp.Topic
+	}
+	fields := []any{logger.FieldTopic, topic, logger.FieldPartition, tp.Partition, logger.FieldOffset, int64(tp.Offset)}
+	return append(fields, args...)
+}
+
+// partitionsText formata uma lista de partições como "tópico[partição]" separados por vírgula
+func partitionsText(partitions []kafka.TopicPartition) string {
+	parts := make([]string, 0, len(partitions))
+	for _, tp := range partitions {
+		topic := ""
+		if tp.Topic != nil {
+			topic = *tp.Topic
+		}
+		parts = append(parts, fmt.Sprintf("%s[%d]", topic, tp.Partition))
+	}
+	return strings.Join(parts, ",")
+}
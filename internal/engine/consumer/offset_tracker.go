@@ -0,0 +1,314 @@
+package engine
+
+import (
+	"sync"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// partitionKey identifica uma partição de um tópico
+type partitionKey struct {
+	topic     string
+	partition int32
+}
+
+// partitionOffsets mantém os offsets despachados e concluídos de uma partição.
+// Os offsets pendentes são mantidos na ordem em que foram lidos do Kafka.
+type partitionOffsets struct {
+	pending   []int64
+	completed map[int64]struct{}
+	next      kafka.Offset
+	dirty     bool
+	running   int
+	rewind    kafka.Offset  // Offset em que a partição deve ser reposicionada após uma falha
+	stale     map[int64]int // Execuções em andamento de offsets descartados pelo reposicionamento
+}
+
+// offsetTracker controla quais offsets podem ser confirmados com segurança.
+//
+// Com processamento paralelo as mensagens de uma partição podem terminar fora de ordem.
+// O tracker só libera para commit o maior offset contíguo já concluído, garantindo que
+// nenhuma mensagem ainda em processamento seja confirmada em caso de falha.
+type offsetTracker struct {
+	mu         sync.Mutex
+	idle       *sync.Cond
+	partitions map[partitionKey]*partitionOffsets
+	inFlight   int
+}
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// newOffsetTracker cria um tracker vazio
+func newOffsetTracker() *offsetTracker {
+	tracker := &offsetTracker{
+		partitions: make(map[partitionKey]*partitionOffsets),
+	}
+	tracker.idle = sync.NewCond(&tracker.mu)
+	return tracker
+}
+
+// ==========================================================================
+// Métodos
+// ==========================================================================
+
+// track registra um offset despachado para processamento.
+// Retorna false, sem registrar, quando a partição aguarda reposicionamento em um offset anterior:
+// a mensagem será lida novamente e não deve ser despachada.
+func (t *offsetTracker) track(tp kafka.TopicPartition) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := partitionKey{topic: *tp.Topic, partition: tp.Partition}
+	offsets, exists := t.partitions[key]
+	if exists && offsets.rewinding(tp.Offset) {
+		return false
+	}
+	if !exists {
+		offsets = &partitionOffsets{
+			completed: make(map[int64]struct{}),
+			next:      kafka.OffsetInvalid,
+			rewind:    kafka.OffsetInvalid,
+			stale:     make(map[int64]int),
+		}
+		t.partitions[key] = offsets
+	}
+
+	offsets.pending = append(offsets.pending, int64(tp.Offset))
+	offsets.running++
+	t.inFlight++
+	return true
+}
+
+// rewinding indica se a partição aguarda reposicionamento em um offset anterior ao informado
+func (t *offsetTracker) rewinding(tp kafka.TopicPartition) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	offsets, exists := t.partitions[partitionKey{topic: *tp.Topic, partition: tp.Partition}]
+	return exists && offsets.rewinding(tp.Offset)
+}
+
+// start indica se a execução despachada do offset deve chamar o handler. Uma execução enfileirada antes
+// da falha de um offset anterior da partição foi descartada pelo reposicionamento: ela é encerrada sem
+// chamar o handler, para que a mensagem não seja tratada antes da mensagem falha lida novamente.
+func (t *offsetTracker) start(tp kafka.TopicPartition) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	offsets, exists := t.partitions[partitionKey{topic: *tp.Topic, partition: tp.Partition}]
+	if !exists || !offsets.discarded(int64(tp.Offset)) {
+		return true
+	}
+	t.finish(offsets)
+	return false
+}
+
+// complete marca um offset como processado e avança o offset confirmável da partição
+// enquanto os offsets pendentes mais antigos estiverem concluídos.
+func (t *offsetTracker) complete(tp kafka.TopicPartition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	offsets, exists := t.partitions[partitionKey{topic: *tp.Topic, partition: tp.Partition}]
+	if !exists {
+		return
+	}
+
+	t.finish(offsets)
+	if offsets.discarded(int64(tp.Offset)) {
+		return
+	}
+	offsets.completed[int64(tp.Offset)] = struct{}{}
+
+	for len(offsets.pending) > 0 {
+		head := offsets.pending[0]
+		if _, done := offsets.completed[head]; !done {
+			break
+		}
+		delete(offsets.completed, head)
+		offsets.pending = offsets.pending[1:]
+		offsets.next = kafka.Offset(head + 1)
+		offsets.dirty = true
+	}
+}
+
+// committable retorna, para cada partição que avançou desde a última chamada,
+// o próximo offset a ser confirmado (último offset contíguo concluído + 1).
+func (t *offsetTracker) committable() []kafka.TopicPartition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var result []kafka.TopicPartition
+	for key, offsets := range t.partitions {
+		if !offsets.dirty {
+			continue
+		}
+		topic := key.topic
+		result = append(result, kafka.TopicPartition{
+			Topic:     &topic,
+			Partition: key.partition,
+			Offset:    offsets.next,
+		})
+		offsets.dirty = false
+	}
+
+	return result
+}
+
+// fail registra que o processamento de um offset terminou sem sucesso e solicita o reposicionamento
+// da partição nele. O offset e os posteriores deixam de ser pendentes: as execuções ainda em andamento
+// são descartadas ao terminar, as enfileiradas ao iniciar (start), e todos voltam a ser despachados
+// quando forem lidos novamente.
+func (t *offsetTracker) fail(tp kafka.TopicPartition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	offsets, exists := t.partitions[partitionKey{topic: *tp.Topic, partition: tp.Partition}]
+	if !exists {
+		return
+	}
+
+	t.finish(offsets)
+	failed := int64(tp.Offset)
+	if offsets.discarded(failed) {
+		// Falha de uma execução já descartada: o reposicionamento anterior cobre a mensagem
+		return
+	}
+
+	kept := offsets.pending[:0]
+	for _, offset := range offsets.pending {
+		switch {
+		case offset < failed:
+			kept = append(kept, offset)
+		case offset == failed:
+		default:
+			if _, done := offsets.completed[offset]; done {
+				delete(offsets.completed, offset)
+			} else {
+				offsets.stale[offset]++
+			}
+		}
+	}
+	offsets.pending = kept
+
+	if offsets.rewind == kafka.OffsetInvalid || tp.Offset < offsets.rewind {
+		offsets.rewind = tp.Offset
+	}
+}
+
+// rewinds retorna as partições com reposicionamento solicitado por fail, no offset da mensagem falha
+// mais antiga, e limpa as solicitações
+func (t *offsetTracker) rewinds() []kafka.TopicPartition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var result []kafka.TopicPartition
+	for key, offsets := range t.partitions {
+		if offsets.rewind == kafka.OffsetInvalid {
+			continue
+		}
+		topic := key.topic
+		result = append(result, kafka.TopicPartition{Topic: &topic, Partition: key.partition, Offset: offsets.rewind})
+		offsets.rewind = kafka.OffsetInvalid
+	}
+	return result
+}
+
+// release aguarda o término das mensagens em processamento das partições informadas e as remove
+// do tracker, retornando o próximo offset a confirmar de cada uma que avançou desde o último commit.
+// Usado quando as partições são revogadas no rebalanceamento.
+func (t *offsetTracker) release(partitions []kafka.TopicPartition) []kafka.TopicPartition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var result []kafka.TopicPartition
+	for _, tp := range partitions {
+		key := partitionKey{topic: *tp.Topic, partition: tp.Partition}
+		offsets, exists := t.partitions[key]
+		if !exists {
+			continue
+		}
+
+		for offsets.running > 0 {
+			t.idle.Wait()
+		}
+
+		if offsets.dirty {
+			topic := key.topic
+			result = append(result, kafka.TopicPartition{Topic: &topic, Partition: key.partition, Offset: offsets.next})
+		}
+		delete(t.partitions, key)
+	}
+
+	return result
+}
+
+// finish encerra o processamento de um offset da partição e acorda quem aguarda em release.
+// Deve ser chamado com o mutex adquirido.
+func (t *offsetTracker) finish(offsets *partitionOffsets) {
+	offsets.running--
+	t.inFlight--
+	t.idle.Broadcast()
+}
+
+// rewinding indica se o offset será lido novamente pelo reposicionamento solicitado na partição
+func (offsets *partitionOffsets) rewinding(offset kafka.Offset) bool {
+	return offsets.rewind != kafka.OffsetInvalid && offset >= offsets.rewind
+}
+
+// discarded consome uma execução descartada do offset, indicando se o seu resultado deve ser ignorado.
+// Uma mensagem é roteada sempre ao mesmo worker, portanto a execução descartada termina antes
+// da execução da mesma mensagem lida novamente.
+func (offsets *partitionOffsets) discarded(offset int64) bool {
+	if offsets.stale[offset] == 0 {
+		return false
+	}
+	offsets.stale[offset]--
+	if offsets.stale[offset] == 0 {
+		delete(offsets.stale, offset)
+	}
+	return true
+}
+
+// pending retorna o número de mensagens despachadas e ainda em processamento
+func (t *offsetTracker) pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.inFlight
+}
+
+// markProcessed registra a mensagem como a última concluída da sua partição
+func markProcessed(processed map[partitionKey]kafka.TopicPartition, tp kafka.TopicPartition) {
+	next := tp
+	next.Offset++
+	processed[partitionKey{topic: *tp.Topic, partition: tp.Partition}] = next
+}
+
+// releaseProcessed remove as partições informadas do registro de markProcessed,
+// retornando os próximos offsets a confirmar das que tinham mensagens concluídas
+func releaseProcessed(processed map[partitionKey]kafka.TopicPartition, partitions []kafka.TopicPartition) []kafka.TopicPartition {
+	var offsets []kafka.TopicPartition
+	for _, tp := range partitions {
+		key := partitionKey{topic: *tp.Topic, partition: tp.Partition}
+		if next, exists := processed[key]; exists {
+			offsets = append(offsets, next)
+			delete(processed, key)
+		}
+	}
+	return offsets
+}
+
+// processedOffsets retorna os próximos offsets a confirmar registrados por markProcessed
+func processedOffsets(processed map[partitionKey]kafka.TopicPartition) []kafka.TopicPartition {
+	offsets := make([]kafka.TopicPartition, 0, len(processed))
+	for _, tp := range processed {
+		offsets = append(offsets, tp)
+	}
+	return offsets
+}
@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+// Teste do tracker de offsets do consumo paralelo
+// Garante que apenas o maior offset contíguo concluído é liberado para commit,
+// mesmo quando as mensagens terminam fora de ordem.
+func TestOffsetTrackerCommitsOnlyContiguousOffsets(t *testing.T) {
+	topic := "orders"
+	tp := func(offset int64) kafka.TopicPartition {
+		return kafka.TopicPartition{Topic: &topic, Partition: 0, Offset: kafka.Offset(offset)}
+	}
+
+	tracker := newOffsetTracker()
+	for offset := int64(10); offset < 14; offset++ {
+		tracker.track(tp(offset))
+	}
+
+	// Offsets 11 e 12 concluem antes do 10: nada pode ser confirmado
+	tracker.complete(tp(11))
+	tracker.complete(tp(12))
+	assert.Empty(t, tracker.committable(), "Offsets confirmados antes da mensagem mais antiga terminar!")
+	assert.Equal(t, 2, tracker.pending())
+
+	// Ao concluir o 10, o commit avança até o 12 (próximo offset = 13)
+	tracker.complete(tp(10))
+	offsets := tracker.committable()
+	assert.Len(t, offsets, 1)
+	assert.Equal(t, kafka.Offset(13), offsets[0].Offset)
+
+	// Sem novos avanços não há o que confirmar
+	assert.Empty(t, tracker.committable())
+
+	tracker.complete(tp(13))
+	offsets = tracker.committable()
+	assert.Len(t, offsets, 1)
+	assert.Equal(t, kafka.Offset(14), offsets[0].Offset)
+	assert.Equal(t, 0, tracker.pending())
+}
+
+// Teste de isolamento entre partições
+// O avanço de uma partição não pode depender de mensagens pendentes em outra.
+func TestOffsetTrackerIsolatesPartitions(t *testing.T) {
+	topic := "orders"
+	tracker := newOffsetTracker()
+
+	tracker.track(kafka.TopicPartition{Topic: &topic, Partition: 0, Offset: 5})
+	tracker.track(kafka.TopicPartition{Topic: &topic, Partition: 1, Offset: 7})
+
+	tracker.complete(kafka.TopicPartition{Topic: &topic, Partition: 1, Offset: 7})
+
+	offsets := tracker.committable()
+	assert.Len(t, offsets, 1)
+	assert.Equal(t, int32(1), offsets[0].Partition)
+	assert.Equal(t, kafka.Offset(8), offsets[0].Offset)
+}
+
+// Teste da liberação de partições revogadas
+// A liberação aguarda as mensagens em processamento da partição e retorna o offset a confirmar,
+// sem afetar as demais partições.
+func TestOffsetTrackerReleaseWaitsForRunningMessages(t *testing.T) {
+	topic := "orders"
+	tp := func(partition int32, offset int64) kafka.TopicPartition {
+		return kafka.TopicPartition{Topic: &topic, Partition: partition, Offset: kafka.Offset(offset)}
+	}
+
+	tracker := newOffsetTracker()
+	tracker.track(tp(0, 5))
+	tracker.track(tp(0, 6))
+	tracker.track(tp(1, 9))
+	tracker.complete(tp(0, 5))
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		tracker.complete(tp(0, 6))
+	}()
+
+	offsets := tracker.release([]kafka.TopicPartition{tp(0, 0)})
+	assert.Len(t, offsets, 1)
+	assert.Equal(t, kafka.Offset(7), offsets[0].Offset, "A liberação deveria aguardar a mensagem em processamento")
+	assert.Equal(t, 1, tracker.pending(), "A partição não revogada deveria continuar pendente")
+
+	// Mensagens falhas não bloqueiam a liberação, mas também não são confirmadas
+	tracker.fail(tp(1, 9))
+	assert.Empty(t, tracker.release([]kafka.TopicPartition{tp(1, 0)}))
+	assert.Equal(t, 0, tracker.pending())
+}
+
+// Teste do reposicionamento após uma falha no consumo paralelo
+// A partição volta à mensagem falha, as posteriores em andamento são descartadas e,
+// lidas novamente, avançam o commit normalmente.
+func TestOffsetTrackerRewindsFailedPartition(t *testing.T) {
+	topic := "orders"
+	tp := func(offset int64) kafka.TopicPartition {
+		return kafka.TopicPartition{Topic: &topic, Partition: 0, Offset: kafka.Offset(offset)}
+	}
+
+	tracker := newOffsetTracker()
+	for offset := int64(10); offset < 14; offset++ {
+		assert.True(t, tracker.track(tp(offset)))
+	}
+	tracker.complete(tp(10))
+	tracker.complete(tp(12))
+
+	// O offset 11 falha enquanto o 13 ainda está em processamento
+	tracker.fail(tp(11))
+	assert.Equal(t, kafka.Offset(11), tracker.committable()[0].Offset, "O commit não deveria passar da mensagem falha")
+
+	// Mensagens lidas antes do reposicionamento não são despachadas
+	assert.True(t, tracker.rewinding(tp(14)))
+	assert.False(t, tracker.track(tp(14)))
+	assert.False(t, tracker.rewinding(tp(10)))
+
+	assert.Equal(t, []kafka.TopicPartition{tp(11)}, tracker.rewinds())
+	assert.Empty(t, tracker.rewinds(), "A solicitação de reposicionamento deveria ser consumida")
+
+	// A execução descartada do 13 termina sem avançar o commit
+	tracker.complete(tp(13))
+	assert.Empty(t, tracker.committable())
+	assert.Equal(t, 0, tracker.pending())
+}
+
+// Teste da releitura após o reposicionamento
+// Depois de reposicionada, a partição volta a despachar as mensagens a partir da falha.
+func TestOffsetTrackerTracksRedeliveredMessages(t *testing.T) {
+	topic := "orders"
+	tp := func(offset int64) kafka.TopicPartition {
+		return kafka.TopicPartition{Topic: &topic, Partition: 0, Offset: kafka.Offset(offset)}
+	}
+
+	tracker := newOffsetTracker()
+	tracker.track(tp(5))
+	tracker.track(tp(6))
+	tracker.fail(tp(5))
+	tracker.rewinds()
+
+	// O offset 5 é lido novamente antes de a execução descartada do 6 terminar
+	assert.True(t, tracker.track(tp(5)))
+	tracker.complete(tp(6))
+	assert.True(t, tracker.track(tp(6)))
+	tracker.complete(tp(5))
+	tracker.complete(tp(6))
+
+	offsets := tracker.committable()
+	assert.Len(t, offsets, 1)
+	assert.Equal(t, kafka.Offset(7), offsets[0].Offset)
+	assert.Equal(t, 0, tracker.pending())
+}
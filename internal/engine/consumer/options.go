@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/internal/engine/middleware"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// ConsumeOptions reúne as configurações opcionais de uma assinatura.
+// O valor zero mantém o comportamento padrão do consumidor.
+type ConsumeOptions struct {
+	// Topics define tópicos adicionais assinados junto com o tópico principal.
+	// Nomes iniciados por "^" são expressões regulares resolvidas pelo librdkafka.
+	Topics []string
+	// Partitions atribui manualmente estas partições de cada tópico, sem participar do rebalanceamento.
+	// Sem GroupId a assinatura usa um grupo efêmero e não confirma offsets.
+	Partitions []int32
+	// Bounded encerra o consumo quando todas as partições atribuídas alcançam o offset final
+	// que tinham na atribuição
+	Bounded bool
+	// GroupId substitui o grupo de consumidores de KAFKA_GROUPID
+	GroupId string
+	// AutoOffsetReset substitui o auto.offset.reset do ambiente e da prioridade
+	AutoOffsetReset enums.AutoOffsetReset
+	// Priority substitui a prioridade de KAFKA_CONSUMER_PRIORITY
+	Priority enums.ConsumerPriority
+	// CommitMode define como os offsets das mensagens tratadas são confirmados (padrão: PERIODIC)
+	CommitMode enums.CommitMode
+	// CommitInterval substitui o intervalo do commit periódico (auto.commit.interval.ms)
+	CommitInterval time.Duration
+	// AssignmentStrategy substitui o partition.assignment.strategy de KAFKA_PARTITION_ASSIGNMENT_STRATEGY
+	AssignmentStrategy enums.AssignmentStrategy
+	// Rebalance define os hooks chamados na atribuição, revogação e perda de partições
+	Rebalance RebalanceHooks
+	// StartPosition define onde a assinatura começa a consumir cada partição, ignorando o offset confirmado
+	StartPosition StartPosition
+	// Deduplication confirma sem chamar o handler as mensagens cuja chave já foi processada
+	Deduplication DeduplicationOptions
+	// Middlewares envolvem cada execução do handler, na ordem informada (o primeiro é o mais externo)
+	Middlewares []middleware.Middleware
+	// HandlerTimeout define o prazo de cada mensagem de um handler com contexto, compartilhado pelas
+	// retentativas (padrão e limite: 80% do max.poll.interval.ms da assinatura)
+	HandlerTimeout time.Duration
+	// Watchdog define o que fazer quando um handler com contexto excede o prazo (padrão: REPORT)
+	Watchdog enums.WatchdogAction
+	// RetryPolicy define as retentativas em memória quando o handler retorna erro
+	RetryPolicy RetryPolicy
+	// DeadLetterTopic é o tópico que recebe as mensagens que esgotaram as retentativas, as indecodificáveis
+	// (OnDeserializationFailedSendToDeadLetter) e as rejeitadas com DeadLetter (padrão: "<tópico>.dlq")
+	DeadLetterTopic string
+	// RetryTopicDelays define os atrasos dos tópicos de retentativa (<tópico>.retry.<atraso>), em ordem
+	RetryTopicDelays []time.Duration
+	// DeserializationFailureCallback é invocado pela estratégia OnDeserializationFailedInvokeCallback
+	DeserializationFailureCallback DeserializationFailureCallback
+	// Backpressure define os limites de mensagens em processamento que pausam e retomam o consumo paralelo
+	Backpressure BackpressureOptions
+	// Controller permite encerrar a assinatura explicitamente com Close
+	Controller *Controller
+}
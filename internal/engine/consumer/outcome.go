@@ -0,0 +1,174 @@
+package engine
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// Outcome indica ao consumidor o que fazer com uma mensagem após o handler.
+// O valor zero equivale a Ack.
+type Outcome struct {
+	action outcomeAction
+	after  time.Duration
+	reason string
+}
+
+// outcomeAction identifica a ação de um Outcome
+type outcomeAction int
+
+const (
+	outcomeAck outcomeAction = iota
+	outcomeRetry
+	outcomeDeadLetter
+	outcomeSkip
+)
+
+// OutcomeHandler processa uma mensagem e retorna o destino dela
+type OutcomeHandler[TData any] func(message message.Message[TData]) Outcome
+
+// disposition indica ao laço de consumo o que foi feito com uma mensagem processada
+type disposition int
+
+const (
+	// dispositionHandled indica que a mensagem foi tratada e seu offset pode ser confirmado
+	dispositionHandled disposition = iota
+	// dispositionSkipped indica que a mensagem foi consumida sem confirmação
+	dispositionSkipped
+	// dispositionRedelivered indica que a partição foi reposicionada na mensagem, que será consumida novamente
+	dispositionRedelivered
+)
+
+// messageProcessor processa uma mensagem deserializada e retorna a sua disposição
+type messageProcessor[TData any] func(sub *subscription, record *kafka.Message, baseMessage message.Message[TData]) disposition
+
+// processorFactory cria o processador de mensagens de uma instância do consumidor. Os consumidores dos
+// níveis de retentativa criam o seu próprio processador, que reposiciona as partições do seu cliente.
+type processorFactory[TData any] func(c *kafkaConsumer[TData]) messageProcessor[TData]
+
+// attemptHandler executa uma tentativa de processamento da mensagem recebendo o prazo da mensagem,
+// compartilhado por todas as tentativas (zero quando a assinatura não define prazo)
+type attemptHandler[TData any] func(deadline time.Time, message message.Message[TData]) error
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// Ack confirma a mensagem
+func Ack() Outcome {
+	return Outcome{action: outcomeAck}
+}
+
+// Retry reposiciona a partição na mensagem e a pausa pelo tempo informado; a mensagem é entregue
+// novamente ao handler após o intervalo, antes das mensagens seguintes da partição
+func Retry(after time.Duration) Outcome {
+	return Outcome{action: outcomeRetry, after: after}
+}
+
+// DeadLetter encaminha a mensagem ao tópico de dead-letter com o motivo informado e a confirma
+func DeadLetter(reason string) Outcome {
+	return Outcome{action: outcomeDeadLetter, reason: reason}
+}
+
+// Skip segue para a próxima mensagem sem confirmar esta. O offset da partição ainda avança
+// além dela quando uma mensagem seguinte for confirmada.
+func Skip() Outcome {
+	return Outcome{action: outcomeSkip}
+}
+
+// ==========================================================================
+// Métodos Públicos
+// ==========================================================================
+
+// ConsumeWithOutcome inicia o consumo sequencial de um tópico Kafka com um handler que decide o destino
+// de cada mensagem: Ack confirma, Retry reposiciona e pausa a partição, DeadLetter encaminha à dead-letter
+// e Skip segue sem confirmar. A RetryPolicy e os tópicos de retentativa das opções não se aplicam.
+// Quando um middleware retorna erro a mensagem volta a ser consumida.
+//
+// Parâmetros:
+//   - topic: Nome do tópico Kafka para consumo
+//   - deserialization: Formato de deserialização a ser usado
+//   - strategy: Estratégia de tratamento de erro de deserialização
+//   - options: Configurações opcionais da assinatura (dead-letter, commit, controlador)
+//   - handler: Função chamada para cada mensagem, que retorna o destino dela
+//
+// Retorno:
+//   - error: Erro caso ocorra falha no consumo, incluindo *DeserializationError quando a estratégia encerra o consumo
+func (c *kafkaConsumer[TData]) ConsumeWithOutcome(topic string, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, options ConsumeOptions, handler OutcomeHandler[TData]) error {
+	// O próprio handler decide quando repetir a mensagem
+	options.RetryTopicDelays = nil
+	options.RetryPolicy = RetryPolicy{}
+
+	return c.consume(topic, deserialization, strategy, options, func(c *kafkaConsumer[TData]) messageProcessor[TData] {
+		return c.outcomeProcessor(handler)
+	})
+}
+
+// outcomeProcessor retorna o processador de ConsumeWithOutcome, que aplica o destino escolhido pelo handler
+func (c *kafkaConsumer[TData]) outcomeProcessor(handler OutcomeHandler[TData]) messageProcessor[TData] {
+	return func(sub *subscription, record *kafka.Message, baseMessage message.Message[TData]) disposition {
+		key, seen, err := c.duplicate(sub, record)
+		if err != nil {
+			c.rewind(record.TopicPartition)
+			return dispositionRedelivered
+		}
+		if seen {
+			return dispositionHandled
+		}
+
+		var outcome Outcome
+		err = c.intercept(sub, record, baseMessage, func(message message.Message[TData]) error {
+			outcome = handler(message)
+			return nil
+		})
+		if err != nil {
+			// Um middleware interrompeu o handler: a mensagem volta a ser consumida
+			c.logger.Error("Error on handle message", recordFields(record, logger.FieldError, err)...)
+			c.rewind(record.TopicPartition)
+			return dispositionRedelivered
+		}
+		if outcome.action == outcomeAck {
+			c.remember(sub, record, key)
+		}
+		return c.applyOutcome(sub, record, outcome)
+	}
+}
+
+// ==========================================================================
+// Métodos Privados
+// ==========================================================================
+
+// applyOutcome executa a ação do Outcome retornado pelo handler para a mensagem
+func (c *kafkaConsumer[TData]) applyOutcome(sub *subscription, record *kafka.Message, outcome Outcome) disposition {
+	switch outcome.action {
+	case outcomeRetry:
+		c.logger.Debug("Message will be redelivered", recordFields(record, "after", outcome.after)...)
+		if outcome.after <= 0 || !sub.delayed.delay(c.client, record.TopicPartition, time.Now().Add(outcome.after)) {
+			c.rewind(record.TopicPartition)
+		}
+		return dispositionRedelivered
+	case outcomeDeadLetter:
+		deadLetterTopic := sub.deadLetterTopic(record)
+		if err := forwardToDeadLetter(c.forwarder(), deadLetterTopic, record, errors.New(outcome.reason), 1); err != nil {
+			// A mensagem não pôde ser encaminhada: volta a ser consumida
+			c.logger.Error("Error on forward message to dead-letter topic", recordFields(record, "deadLetterTopic", deadLetterTopic, logger.FieldError, err)...)
+			c.rewind(record.TopicPartition)
+			return dispositionRedelivered
+		}
+		c.logger.Warn("Message forwarded to dead-letter topic", recordFields(record, "deadLetterTopic", deadLetterTopic, "reason", outcome.reason)...)
+		return dispositionHandled
+	case outcomeSkip:
+		c.logger.Debug("Message skipped without commit", recordFields(record)...)
+		return dispositionSkipped
+	default:
+		return dispositionHandled
+	}
+}
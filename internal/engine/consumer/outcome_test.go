@@ -0,0 +1,21 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+// Teste da disposição dos destinos que não dependem do cliente Kafka
+// O valor zero de Outcome equivale a Ack e Skip não confirma a mensagem.
+func TestApplyOutcomeAckAndSkip(t *testing.T) {
+	c := &kafkaConsumer[string]{logger: logger.NewNopLogger()}
+	topic := "orders"
+	record := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 0, Offset: 7}}
+
+	assert.Equal(t, dispositionHandled, c.applyOutcome(nil, record, Ack()))
+	assert.Equal(t, dispositionHandled, c.applyOutcome(nil, record, Outcome{}), "O valor zero deveria confirmar a mensagem")
+	assert.Equal(t, dispositionSkipped, c.applyOutcome(nil, record, Skip()))
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// ParallelOptions define como as mensagens são distribuídas no consumo paralelo
+type ParallelOptions struct {
+	// Workers é o número de goroutines que processam mensagens simultaneamente
+	Workers int
+	// QueueSize é o número de mensagens que cada worker pode manter enfileiradas
+	QueueSize int
+	// Ordering define a chave de ordenação usada para escolher o worker
+	Ordering enums.ParallelOrdering
+}
+
+// parallelDispatcher distribui jobs entre um conjunto fixo de workers.
+// Jobs com a mesma chave de roteamento vão sempre para o mesmo worker, preservando a ordem.
+type parallelDispatcher struct {
+	queues   []chan func()
+	ordering enums.ParallelOrdering
+	wg       sync.WaitGroup
+}
+
+// saturationDelay é o intervalo em que a partição de uma mensagem roteada a um worker com a fila cheia
+// permanece pausada antes de a mensagem ser lida novamente
+const saturationDelay = 50 * time.Millisecond
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// newParallelDispatcher cria e inicia os workers do dispatcher
+func newParallelDispatcher(options ParallelOptions) *parallelDispatcher {
+	queueSize := options.QueueSize
+	if queueSize <= 0 {
+		queueSize = 1
+	}
+
+	dispatcher := &parallelDispatcher{
+		queues:   make([]chan func(), options.Workers),
+		ordering: options.Ordering,
+	}
+
+	for i := range dispatcher.queues {
+		queue := make(chan func(), queueSize)
+		dispatcher.queues[i] = queue
+		dispatcher.wg.Add(1)
+		go func() {
+			defer dispatcher.wg.Done()
+			for job := range queue {
+				job()
+			}
+		}()
+	}
+
+	return dispatcher
+}
+
+// ==========================================================================
+// Métodos
+// ==========================================================================
+
+// dispatch enfileira o job no worker responsável pela mensagem.
+// Bloqueia enquanto a fila do worker estiver cheia; use saturated antes para não bloquear o poll.
+func (d *parallelDispatcher) dispatch(msg *kafka.Message, job func()) {
+	d.queues[d.route(msg)] <- job
+}
+
+// saturated indica se a fila do worker responsável pela mensagem está cheia.
+// Apenas a goroutine de consumo enfileira jobs, portanto uma fila com espaço não fica cheia antes do dispatch.
+func (d *parallelDispatcher) saturated(msg *kafka.Message) bool {
+	queue := d.queues[d.route(msg)]
+	return len(queue) == cap(queue)
+}
+
+// close encerra as filas e aguarda a conclusão dos jobs já enfileirados
+func (d *parallelDispatcher) close() {
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.wg.Wait()
+}
+
+// route calcula o índice do worker de acordo com a ordenação configurada.
+// Mensagens sem chave são roteadas pela partição de origem.
+func (d *parallelDispatcher) route(msg *kafka.Message) int {
+	hash := fnv.New32a()
+
+	if d.ordering == enums.OrderingByKey && len(msg.Key) > 0 {
+		hash.Write(msg.Key)
+	} else {
+		if msg.TopicPartition.Topic != nil {
+			hash.Write([]byte(*msg.TopicPartition.Topic))
+		}
+		hash.Write([]byte(strconv.Itoa(int(msg.TopicPartition.Partition))))
+	}
+
+	return int(hash.Sum32() % uint32(len(d.queues)))
+}
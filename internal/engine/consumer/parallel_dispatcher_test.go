@@ -0,0 +1,231 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/internal/common/setup"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockConsumerSetup cria consumidores conectados a um cluster simulado
+type mockConsumerSetup struct {
+	servers string
+}
+
+// NewKafkaConsumer cria um consumidor do cluster simulado com o grupo da assinatura
+func (s mockConsumerSetup) NewKafkaConsumer(overrides setup.ConsumerOverrides) (*kafka.Consumer, error) {
+	return kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers":        s.servers,
+		"group.id":                 overrides.GroupId + overrides.GroupSuffix,
+		"auto.offset.reset":        "earliest",
+		"enable.auto.offset.store": false,
+	})
+}
+
+// MaxPollInterval retorna o max.poll.interval.ms padrão do librdkafka
+func (s mockConsumerSetup) MaxPollInterval(setup.ConsumerOverrides) time.Duration {
+	return 5 * time.Minute
+}
+
+// Teste do roteamento por chave
+// Mensagens com a mesma chave vão sempre para o mesmo worker; a fila cheia é sinalizada sem bloquear.
+func TestParallelDispatcherSaturated(t *testing.T) {
+	dispatcher := newParallelDispatcher(ParallelOptions{Workers: 2, QueueSize: 1, Ordering: enums.OrderingByKey})
+	topic := "orders"
+	hot := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic}, Key: []byte("hot")}
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	dispatcher.dispatch(hot, func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	assert.False(t, dispatcher.saturated(hot), "O worker ocupado ainda deveria ter espaço na fila")
+	dispatcher.dispatch(hot, func() {})
+	assert.True(t, dispatcher.saturated(hot), "A fila do worker da chave deveria estar cheia")
+
+	close(release)
+	dispatcher.close()
+}
+
+// Teste do consumo paralelo com uma chave saturada
+// A fila cheia do worker pausa apenas a partição da chave quente: o poll continua atendendo as demais
+// partições e as mensagens da chave quente são processadas uma única vez e em ordem.
+func TestConsumeInParallelDoesNotBlockOnSaturatedKey(t *testing.T) {
+	cluster, err := kafka.NewMockCluster(1)
+	require.NoError(t, err)
+	defer cluster.Close()
+	require.NoError(t, cluster.CreateTopic("orders", 2, 1))
+
+	producer, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": cluster.BootstrapServers()})
+	require.NoError(t, err)
+	defer producer.Close()
+
+	// A chave fria deve ser roteada para um worker diferente do da chave quente
+	parallelism := ParallelOptions{Workers: 2, QueueSize: 1, Ordering: enums.OrderingByKey}
+	routing := newParallelDispatcher(parallelism)
+	routing.close()
+	coldKey := ""
+	for i := 0; coldKey == ""; i++ {
+		if candidate := fmt.Sprintf("cold-%d", i); routing.route(&kafka.Message{Key: []byte(candidate)}) != routing.route(&kafka.Message{Key: []byte("hot")}) {
+			coldKey = candidate
+		}
+	}
+
+	produce := func(partition int32, key string, count int) {
+		topic := "orders"
+		deliveries := make(chan kafka.Event, count)
+		for i := 0; i < count; i++ {
+			require.NoError(t, producer.Produce(&kafka.Message{
+				TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: partition},
+				Key:            []byte(key),
+				Value:          []byte(fmt.Sprintf("%q", fmt.Sprint(i))),
+			}, deliveries))
+		}
+		for i := 0; i < count; i++ {
+			require.NoError(t, (<-deliveries).(*kafka.Message).TopicPartition.Error)
+		}
+	}
+	produce(0, "hot", 6)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &kafkaConsumer[string]{ctx: ctx, logger: logger.NewNopLogger(), consumerSetup: mockConsumerSetup{servers: cluster.BootstrapServers()}}
+	c.initializeDeserializers()
+
+	var mu sync.Mutex
+	var hotOffsets []int64
+	cold := make(chan struct{}, 3)
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.ConsumeInParallel("orders", enums.JsonDeserialization, enums.OnDeserializationFailedStopHost, parallelism,
+			ConsumeOptions{GroupId: "orders-group", Partitions: []int32{0, 1}, Backpressure: BackpressureOptions{HighWatermark: 100}},
+			func(msg message.Message[string]) error {
+				if string(msg.Key) != "hot" {
+					cold <- struct{}{}
+					return nil
+				}
+				mu.Lock()
+				hotOffsets = append(hotOffsets, msg.Offset)
+				mu.Unlock()
+				once.Do(func() {
+					close(blocked)
+					<-release
+				})
+				return nil
+			})
+	}()
+
+	// Com o worker da chave quente ocupado e a sua fila cheia, as mensagens da outra partição continuam sendo consumidas
+	select {
+	case <-blocked:
+	case <-time.After(10 * time.Second):
+		t.Fatal("A primeira mensagem da chave quente não foi processada")
+	}
+	time.Sleep(200 * time.Millisecond)
+	produce(1, coldKey, 3)
+	for i := 0; i < 3; i++ {
+		select {
+		case <-cold:
+		case <-time.After(10 * time.Second):
+			t.Fatal("O poll não deveria bloquear com a fila do worker da chave quente cheia")
+		}
+	}
+
+	close(release)
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(hotOffsets) >= 6
+	}, 10*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []int64{0, 1, 2, 3, 4, 5}, hotOffsets, "A chave quente deveria ser processada uma única vez e em ordem")
+}
+
+// Teste da ordem por chave após uma falha no consumo paralelo
+// A mensagem falha que não pôde ser encaminhada reposiciona a partição; a mensagem seguinte da mesma
+// chave, já enfileirada no worker, não é tratada antes da releitura da mensagem falha.
+func TestConsumeInParallelKeepsKeyOrderAfterFailure(t *testing.T) {
+	cluster, err := kafka.NewMockCluster(1)
+	require.NoError(t, err)
+	defer cluster.Close()
+	require.NoError(t, cluster.CreateTopic("orders", 1, 1))
+
+	producer, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": cluster.BootstrapServers()})
+	require.NoError(t, err)
+	defer producer.Close()
+
+	topic := "orders"
+	deliveries := make(chan kafka.Event, 2)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, producer.Produce(&kafka.Message{
+			TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 0},
+			Key:            []byte("A"),
+			Value:          []byte(fmt.Sprintf("%q", fmt.Sprint(i))),
+		}, deliveries))
+	}
+	for i := 0; i < 2; i++ {
+		require.NoError(t, (<-deliveries).(*kafka.Message).TopicPartition.Error)
+	}
+
+	// Sem produtor a mensagem falha não pode ser encaminhada à dead-letter e a partição é reposicionada
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &kafkaConsumer[string]{ctx: ctx, logger: logger.NewNopLogger(), consumerSetup: mockConsumerSetup{servers: cluster.BootstrapServers()}}
+	c.initializeDeserializers()
+
+	var mu sync.Mutex
+	var handled []int64
+	failed := false
+	done := make(chan error, 1)
+	go func() {
+		done <- c.ConsumeInParallel("orders", enums.JsonDeserialization, enums.OnDeserializationFailedStopHost,
+			ParallelOptions{Workers: 2, QueueSize: 4, Ordering: enums.OrderingByKey},
+			ConsumeOptions{GroupId: "orders-group", Partitions: []int32{0}},
+			func(msg message.Message[string]) error {
+				mu.Lock()
+				handled = append(handled, msg.Offset)
+				first := msg.Offset == 0 && !failed
+				failed = failed || first
+				mu.Unlock()
+				if first {
+					// Aguarda a mensagem seguinte da chave ser enfileirada no mesmo worker
+					time.Sleep(300 * time.Millisecond)
+					return fmt.Errorf("falha")
+				}
+				return nil
+			})
+	}()
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(handled) > 0 && handled[len(handled)-1] == 1
+	}, 10*time.Second, 10*time.Millisecond)
+	time.Sleep(200 * time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []int64{0, 0, 1}, handled, "A mensagem seguinte da chave não deveria ser tratada antes da releitura da falha")
+}
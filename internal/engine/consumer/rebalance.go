@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// RebalanceHooks reúne as funções chamadas nos eventos de rebalanceamento de uma assinatura.
+// As funções são executadas na goroutine de consumo, durante o poll: nenhuma mensagem nova é
+// entregue enquanto executam. Funções nil são ignoradas.
+type RebalanceHooks struct {
+	// OnAssigned é chamada após a atribuição das partições e antes da entrega da primeira mensagem delas.
+	// Permite preparar o estado por partição usado pelo handler.
+	OnAssigned func(partitions []kafka.TopicPartition)
+	// OnRevoked é chamada após a confirmação dos offsets processados das partições revogadas,
+	// antes que elas sejam entregues a outro membro do grupo.
+	OnRevoked func(partitions []kafka.TopicPartition)
+	// OnLost é chamada quando as partições foram perdidas involuntariamente, por exemplo após
+	// exceder max.poll.interval.ms. Os offsets não são confirmados, pois outro membro já pode tê-las.
+	OnLost func(partitions []kafka.TopicPartition)
+}
+
+// cooperativeProtocol é o protocolo de rebalanceamento retornado pelo librdkafka
+// para estratégias incrementais, como cooperative-sticky
+const cooperativeProtocol = "COOPERATIVE"
+
+// ==========================================================================
+// Métodos Privados
+// ==========================================================================
+
+// rebalanceCallback retorna o callback de rebalanceamento da assinatura.
+// Atribui e revoga as partições conforme o protocolo do grupo (Assign/Unassign no eager,
+// IncrementalAssign/IncrementalUnassign no cooperativo), confirma os offsets processados das
+// partições revogadas e invoca os hooks configurados.
+func (c *kafkaConsumer[TData]) rebalanceCallback(sub *subscription) kafka.RebalanceCb {
+	return func(client *kafka.Consumer, event kafka.Event) error {
+		protocol := client.GetRebalanceProtocol()
+
+		switch ev := event.(type) {
+		case kafka.AssignedPartitions:
+			c.logger.Info("Partitions assigned", "protocol", protocol, "count", len(ev.Partitions), "partitions", partitionsText(ev.Partitions))
+			if err := c.assign(client, protocol, sub.startAt(client, ev.Partitions)); err != nil {
+				c.logger.Error("Error on assign partitions", "protocol", protocol, logger.FieldError, err)
+				return err
+			}
+			sub.assigned(client, ev.Partitions)
+
+			if hook := sub.options.Rebalance.OnAssigned; hook != nil {
+				hook(ev.Partitions)
+			}
+		case kafka.RevokedPartitions:
+			c.logger.Info("Partitions revoked", "protocol", protocol, "count", len(ev.Partitions), "partitions", partitionsText(ev.Partitions))
+			offsets := sub.release(ev.Partitions)
+
+			if client.AssignmentLost() {
+				c.logger.Warn("Assignment lost involuntarily, processed offsets will not be committed", "partitions", partitionsText(ev.Partitions))
+				if hook := sub.options.Rebalance.OnLost; hook != nil {
+					hook(ev.Partitions)
+				}
+			} else {
+				c.commitFinal(client, sub, offsets, ev.Partitions)
+				if hook := sub.options.Rebalance.OnRevoked; hook != nil {
+					hook(ev.Partitions)
+				}
+			}
+
+			if err := c.unassign(client, protocol, ev.Partitions); err != nil {
+				c.logger.Error("Error on unassign partitions", "protocol", protocol, logger.FieldError, err)
+				return err
+			}
+		default:
+			c.logger.Warn("Unexpected rebalance event", "protocol", protocol, "event", event.String())
+		}
+
+		return nil
+	}
+}
+
+// assign atribui as partições de acordo com o protocolo de rebalanceamento do grupo
+func (c *kafkaConsumer[TData]) assign(client *kafka.Consumer, protocol string, partitions []kafka.TopicPartition) error {
+	if protocol == cooperativeProtocol {
+		return client.IncrementalAssign(partitions)
+	}
+	return client.Assign(partitions)
+}
+
+// unassign remove as partições revogadas de acordo com o protocolo de rebalanceamento do grupo
+func (c *kafkaConsumer[TData]) unassign(client *kafka.Consumer, protocol string, partitions []kafka.TopicPartition) error {
+	if protocol == cooperativeProtocol {
+		return client.IncrementalUnassign(partitions)
+	}
+	return client.Unassign()
+}
@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// Valores padrão da política de retentativa
+const (
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMultiplier     = 2.0
+)
+
+// maxRetryBackoff é o maior intervalo representável por time.Duration, usado como limite quando MaxBackoff é 0
+const maxRetryBackoff = time.Duration(math.MaxInt64)
+
+// RetryPolicy define as retentativas em memória feitas quando o handler retorna erro.
+// O intervalo entre tentativas cresce exponencialmente a partir de InitialBackoff,
+// limitado por MaxBackoff, com variação aleatória definida por Jitter.
+type RetryPolicy struct {
+	// MaxRetries é o número de novas tentativas após a primeira falha (0 = sem retentativas)
+	MaxRetries int
+	// InitialBackoff é o intervalo antes da primeira retentativa
+	InitialBackoff time.Duration
+	// MaxBackoff limita o intervalo entre tentativas (0 = limitado apenas pelo maior time.Duration)
+	MaxBackoff time.Duration
+	// Multiplier é o fator de crescimento do intervalo a cada tentativa
+	Multiplier float64
+	// Jitter é a fração (0 a 1) do intervalo usada como variação aleatória
+	Jitter float64
+}
+
+// ==========================================================================
+// Métodos
+// ==========================================================================
+
+// Backoff calcula o intervalo de espera antes da retentativa informada (começando em 1)
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = defaultRetryInitialBackoff
+	}
+
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = defaultRetryMultiplier
+	}
+
+	delay := float64(initial) * math.Pow(multiplier, float64(retry-1))
+	limit := maxRetryBackoff
+	if p.MaxBackoff > 0 {
+		limit = p.MaxBackoff
+	}
+	// Sem MaxBackoff o crescimento exponencial excede o limite de time.Duration com muitas retentativas
+	if delay > float64(limit) {
+		delay = float64(limit)
+	}
+
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		delay += delay * jitter * (rand.Float64()*2 - 1)
+	}
+
+	if delay >= float64(maxRetryBackoff) {
+		return maxRetryBackoff
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/internal/engine/middleware"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Teste do backoff exponencial da política de retentativa
+// Garante o crescimento exponencial, o limite máximo e a faixa de variação do jitter.
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		MaxRetries:     5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.Backoff(2))
+	assert.Equal(t, 400*time.Millisecond, policy.Backoff(3))
+	assert.Equal(t, time.Second, policy.Backoff(5), "Backoff deveria respeitar o limite máximo")
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.Backoff(2)
+		assert.GreaterOrEqual(t, delay, 100*time.Millisecond)
+		assert.LessOrEqual(t, delay, 300*time.Millisecond)
+	}
+
+	unbounded := RetryPolicy{InitialBackoff: time.Second, Multiplier: 10, Jitter: 0.5}
+	for retry := 1; retry <= 1000; retry++ {
+		assert.Positive(t, unbounded.Backoff(retry), "Sem MaxBackoff o intervalo não deveria exceder o limite de time.Duration")
+	}
+}
+
+// Teste dos cabeçalhos acrescentados por middlewares nas retentativas
+// Cada tentativa parte dos cabeçalhos originais e o registro encaminhado à dead-letter não recebe os
+// cabeçalhos acrescentados pelos middlewares.
+func TestRetriesDoNotAccumulateMiddlewareHeaders(t *testing.T) {
+	cluster, err := kafka.NewMockCluster(1)
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	producer, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": cluster.BootstrapServers()})
+	require.NoError(t, err)
+	defer producer.Close()
+
+	var counts []int
+	tracing := func(next middleware.Handler) middleware.Handler {
+		return func(record *kafka.Message) error {
+			record.Headers = append(record.Headers, kafka.Header{Key: "trace-id", Value: []byte("abc")})
+			count := 0
+			for _, header := range record.Headers {
+				if header.Key == "trace-id" {
+					count++
+				}
+			}
+			counts = append(counts, count)
+			return next(record)
+		}
+	}
+
+	c := &kafkaConsumer[string]{ctx: context.Background(), logger: logger.NewNopLogger(), producer: producer}
+	options := ConsumeOptions{RetryPolicy: RetryPolicy{MaxRetries: 2}, Middlewares: []middleware.Middleware{tracing}}
+	sub, err := newSubscription("orders", options, c.logger)
+	require.NoError(t, err)
+
+	topic := "orders"
+	record := &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 0, Offset: 7},
+		Value:          []byte(`"pedido"`),
+		Headers:        []kafka.Header{{Key: "source", Value: []byte("api")}},
+	}
+	err = c.handleMessage(sub, record, message.Message[string]{Metadata: map[string][]byte{}}, func(message.Message[string]) error {
+		return errors.New("falha")
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{1, 1, 1}, counts, "O cabeçalho deveria aparecer uma única vez em cada tentativa")
+	assert.Equal(t, []kafka.Header{{Key: "source", Value: []byte("api")}}, record.Headers, "O registro original não deveria ser alterado")
+
+	consumer, err := kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers": cluster.BootstrapServers(),
+		"group.id":          "dlq-test",
+		"auto.offset.reset": "earliest",
+	})
+	require.NoError(t, err)
+	defer consumer.Close()
+	require.NoError(t, consumer.Subscribe("orders.dlq", nil))
+
+	forwarded, err := consumer.ReadMessage(10 * time.Second)
+	require.NoError(t, err)
+	for _, header := range forwarded.Headers {
+		assert.NotEqual(t, "trace-id", header.Key, "Os cabeçalhos dos middlewares não deveriam chegar à dead-letter")
+	}
+}
+
+// Teste da falha por tempo limite do middleware Timeout
+// A mensagem que excede o tempo limite não é repetida em memória, pois o handler ainda está em execução
+// e a retentativa executaria a mesma mensagem duas vezes ao mesmo tempo.
+func TestHandleMessageDoesNotRetryTimedOutHandler(t *testing.T) {
+	cluster, err := kafka.NewMockCluster(1)
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	producer, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": cluster.BootstrapServers()})
+	require.NoError(t, err)
+	defer producer.Close()
+
+	c := &kafkaConsumer[string]{ctx: context.Background(), logger: logger.NewNopLogger(), producer: producer}
+	options := ConsumeOptions{RetryPolicy: RetryPolicy{MaxRetries: 3}, Middlewares: []middleware.Middleware{middleware.Timeout(10 * time.Millisecond)}}
+	sub, err := newSubscription("orders", options, c.logger)
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	var running, calls, overlaps atomic.Int32
+	topic := "orders"
+	record := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic}}
+	err = c.handleMessage(sub, record, message.Message[string]{Metadata: map[string][]byte{}}, func(message.Message[string]) error {
+		calls.Add(1)
+		if running.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+		defer running.Add(-1)
+		<-release
+		return nil
+	})
+	close(release)
+
+	assert.NoError(t, err, "A mensagem deveria seguir para a dead-letter após a falha")
+	assert.Equal(t, int32(1), calls.Load(), "O handler que excedeu o tempo limite não deveria ser repetido pela RetryPolicy")
+	assert.Zero(t, overlaps.Load(), "A mesma mensagem não deveria executar duas vezes ao mesmo tempo")
+}
@@ -0,0 +1,297 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Cabeçalhos dos Tópicos de Retentativa
+// ==========================================================================
+
+// Cabeçalhos adicionados às mensagens republicadas nos tópicos de retentativa
+const (
+	// RetryHeaderOriginalTopic contém o tópico principal da assinatura
+	RetryHeaderOriginalTopic = "x-retry-original-topic"
+	// RetryHeaderOriginalPartition contém a partição da mensagem no tópico principal
+	RetryHeaderOriginalPartition = "x-retry-original-partition"
+	// RetryHeaderOriginalOffset contém o offset da mensagem no tópico principal
+	RetryHeaderOriginalOffset = "x-retry-original-offset"
+	// RetryHeaderAttempt contém o nível de retentativa em que a mensagem se encontra (começando em 1)
+	RetryHeaderAttempt = "x-retry-attempt"
+	// RetryHeaderDueAt contém o instante, em milissegundos Unix, a partir do qual a mensagem pode ser processada
+	RetryHeaderDueAt = "x-retry-due-at"
+	// RetryHeaderError contém a mensagem do último erro do handler
+	RetryHeaderError = "x-retry-error"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// retryTiers descreve os tópicos de retentativa de uma assinatura.
+// Cada nível possui um atraso fixo; após o último nível a mensagem segue para a dead-letter.
+type retryTiers struct {
+	topic           string
+	delays          []time.Duration
+	topics          []string
+	levels          map[string]int
+	deadLetterTopic string
+}
+
+// delayedPartitions controla as partições pausadas até o vencimento da próxima mensagem
+type delayedPartitions struct {
+	resumeAt   map[partitionKey]time.Time
+	partitions map[partitionKey]kafka.TopicPartition
+	logger     logger.ILogger
+}
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// newRetryTiers cria a descrição dos níveis de retentativa do tópico.
+// Retorna nil quando nenhum atraso foi configurado.
+func newRetryTiers(topic string, delays []time.Duration, deadLetterTopic string) *retryTiers {
+	if len(delays) == 0 {
+		return nil
+	}
+
+	if deadLetterTopic == "" {
+		deadLetterTopic = topic + ".dlq"
+	}
+
+	tiers := &retryTiers{
+		topic:           topic,
+		delays:          delays,
+		levels:          make(map[string]int, len(delays)),
+		deadLetterTopic: deadLetterTopic,
+	}
+
+	for i, delay := range delays {
+		name := RetryTopicName(topic, delay)
+		tiers.topics = append(tiers.topics, name)
+		tiers.levels[name] = i + 1
+	}
+
+	return tiers
+}
+
+// newDelayedPartitions cria o controle de partições pausadas
+func newDelayedPartitions(log logger.ILogger) *delayedPartitions {
+	return &delayedPartitions{
+		resumeAt:   make(map[partitionKey]time.Time),
+		partitions: make(map[partitionKey]kafka.TopicPartition),
+		logger:     log,
+	}
+}
+
+// ==========================================================================
+// Funções
+// ==========================================================================
+
+// RetryTopicName retorna o nome do tópico de retentativa de um tópico para o atraso informado,
+// no formato <tópico>.retry.<atraso>, por exemplo "pedidos.retry.1m" ou "pedidos.retry.10m".
+func RetryTopicName(topic string, delay time.Duration) string {
+	return topic + retrySuffix(delay)
+}
+
+// retrySuffix retorna o sufixo ".retry.<atraso>" de um nível de retentativa, usado no nome dos tópicos
+// e no grupo do consumidor do nível
+func retrySuffix(delay time.Duration) string {
+	var suffix string
+	switch {
+	case delay%time.Hour == 0:
+		suffix = fmt.Sprintf("%dh", delay/time.Hour)
+	case delay%time.Minute == 0:
+		suffix = fmt.Sprintf("%dm", delay/time.Minute)
+	case delay%time.Second == 0:
+		suffix = fmt.Sprintf("%ds", delay/time.Second)
+	default:
+		suffix = fmt.Sprintf("%dms", delay/time.Millisecond)
+	}
+	return ".retry." + suffix
+}
+
+// forwardToRetryTier republica a mensagem original no tópico de retentativa,
+// preservando chave, valor e cabeçalhos, e aguarda a confirmação do broker com prazo limitado.
+func forwardToRetryTier(target forwarder, tiers *retryTiers, level int, record *kafka.Message, cause error) error {
+	retryTopic := tiers.topics[level-1]
+	dueAt := time.Now().Add(tiers.delays[level-1]).UnixMilli()
+
+	// As coordenadas no tópico principal são preservadas entre os níveis
+	partition, offset := originalPosition(record)
+
+	headers := make([]kafka.Header, 0, len(record.Headers)+6)
+	for _, header := range record.Headers {
+		switch header.Key {
+		case RetryHeaderOriginalTopic, RetryHeaderOriginalPartition, RetryHeaderOriginalOffset, RetryHeaderAttempt, RetryHeaderDueAt, RetryHeaderError:
+			continue
+		}
+		headers = append(headers, header)
+	}
+	headers = append(headers,
+		kafka.Header{Key: RetryHeaderOriginalTopic, Value: []byte(tiers.topic)},
+		kafka.Header{Key: RetryHeaderOriginalPartition, Value: []byte(strconv.Itoa(int(partition)))},
+		kafka.Header{Key: RetryHeaderOriginalOffset, Value: []byte(strconv.FormatInt(int64(offset), 10))},
+		kafka.Header{Key: RetryHeaderAttempt, Value: []byte(strconv.Itoa(level))},
+		kafka.Header{Key: RetryHeaderDueAt, Value: []byte(strconv.FormatInt(dueAt, 10))},
+		kafka.Header{Key: RetryHeaderError, Value: []byte(errorText(cause))},
+	)
+
+	retry := &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &retryTopic, Partition: kafka.PartitionAny},
+		Key:            record.Key,
+		Value:          record.Value,
+		Headers:        headers,
+	}
+
+	return target.deliver(retry, "de retentativa")
+}
+
+// dueAt retorna o instante de vencimento de uma mensagem de retentativa
+func dueAt(record *kafka.Message) (time.Time, bool) {
+	for _, header := range record.Headers {
+		if header.Key != RetryHeaderDueAt {
+			continue
+		}
+		millis, err := strconv.ParseInt(string(header.Value), 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.UnixMilli(millis), true
+	}
+	return time.Time{}, false
+}
+
+// ==========================================================================
+// Métodos retryTiers
+// ==========================================================================
+
+// subscriptionTopics retorna o tópico principal seguido dos tópicos de retentativa
+func (r *retryTiers) subscriptionTopics() []string {
+	return append([]string{r.topic}, r.topics...)
+}
+
+// level retorna o nível de retentativa do tópico da mensagem (0 para o tópico principal)
+func (r *retryTiers) level(record *kafka.Message) int {
+	return r.levels[topicOf(record)]
+}
+
+// ==========================================================================
+// Métodos delayedPartitions
+// ==========================================================================
+
+// hold pausa a partição da mensagem caso ela ainda não tenha vencido, reposicionando o consumo
+// na própria mensagem. Retorna true quando a mensagem deve aguardar.
+func (d *delayedPartitions) hold(client *kafka.Consumer, record *kafka.Message) bool {
+	due, ok := dueAt(record)
+	if !ok || !time.Now().Before(due) {
+		return false
+	}
+	return d.delay(client, record.TopicPartition, due)
+}
+
+// delay pausa a partição até o instante informado, reposicionando o consumo no offset de tp.
+// Retorna false quando a partição não pôde ser pausada.
+func (d *delayedPartitions) delay(client *kafka.Consumer, tp kafka.TopicPartition, due time.Time) bool {
+	if err := client.Pause([]kafka.TopicPartition{tp}); err != nil {
+		d.logger.Error("Error on pause partition", partitionFields(tp, logger.FieldError, err)...)
+		return false
+	}
+	if err := client.Seek(tp, 0); err != nil {
+		d.logger.Error("Error on seek partition", partitionFields(tp, logger.FieldError, err)...)
+	}
+
+	key := partitionKey{topic: *tp.Topic, partition: tp.Partition}
+	d.resumeAt[key] = due
+	d.partitions[key] = tp
+	return true
+}
+
+// resumeDue retoma as partições cujo prazo de espera venceu.
+// Partições bloqueadas por outro motivo (blocked) permanecem pausadas.
+func (d *delayedPartitions) resumeDue(client *kafka.Consumer, blocked func(partitionKey) bool) {
+	now := time.Now()
+	for key, due := range d.resumeAt {
+		if now.Before(due) {
+			continue
+		}
+		tp := d.partitions[key]
+		if !blocked(key) {
+			if err := client.Resume([]kafka.TopicPartition{tp}); err != nil {
+				d.logger.Error("Error on resume partition", partitionFields(tp, logger.FieldError, err)...)
+			}
+		}
+		delete(d.resumeAt, key)
+		delete(d.partitions, key)
+	}
+}
+
+// holding indica se a partição está pausada aguardando o vencimento de uma mensagem de retentativa
+func (d *delayedPartitions) holding(key partitionKey) bool {
+	_, exists := d.resumeAt[key]
+	return exists
+}
+
+// forget descarta as partições revogadas, que não podem mais ser retomadas por este consumidor
+func (d *delayedPartitions) forget(partitions []kafka.TopicPartition) {
+	for _, tp := range partitions {
+		key := partitionKey{topic: *tp.Topic, partition: tp.Partition}
+		delete(d.resumeAt, key)
+		delete(d.partitions, key)
+	}
+}
+
+// retryConsumers acompanha os consumidores dos níveis de retentativa de uma assinatura.
+// Os métodos aceitam receptor nil, usado quando a assinatura não tem tópicos de retentativa.
+type retryConsumers struct {
+	stop chan struct{}
+	errs chan error
+	wg   sync.WaitGroup
+}
+
+// startRetries inicia um consumidor por nível de retentativa da assinatura, cada um com seu próprio cliente,
+// grupo e laço de consumo, para que o atraso de um nível não retenha a assinatura principal nem os demais níveis
+func (c *kafkaConsumer[TData]) startRetries(sub *subscription, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, processor processorFactory[TData]) *retryConsumers {
+	subs := sub.retrySubscriptions(c.logger)
+	if len(subs) == 0 {
+		return nil
+	}
+
+	retries := &retryConsumers{stop: make(chan struct{}), errs: make(chan error, len(subs))}
+	for _, tierSub := range subs {
+		tier := c.fork()
+		retries.wg.Add(1)
+		go func() {
+			defer retries.wg.Done()
+			if err := tier.run(tierSub, deserialization, strategy, processor(tier), retries.stop, nil, nil); err != nil {
+				retries.errs <- err
+			}
+		}()
+	}
+	return retries
+}
+
+// failed retorna o canal que recebe o erro de um consumidor de retentativa encerrado por falha
+func (r *retryConsumers) failed() <-chan error {
+	if r == nil {
+		return nil
+	}
+	return r.errs
+}
+
+// close encerra os consumidores de retentativa e aguarda que deixem os seus grupos
+func (r *retryConsumers) close() {
+	if r == nil {
+		return
+	}
+	close(r.stop)
+	r.wg.Wait()
+}
@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Teste da nomenclatura e dos níveis dos tópicos de retentativa
+func TestRetryTiersNamingAndLevels(t *testing.T) {
+	tiers := newRetryTiers("orders", []time.Duration{30 * time.Second, time.Minute, 10 * time.Minute, 2 * time.Hour}, "")
+
+	assert.Equal(t, []string{"orders", "orders.retry.30s", "orders.retry.1m", "orders.retry.10m", "orders.retry.2h"}, tiers.subscriptionTopics())
+	assert.Equal(t, "orders.dlq", tiers.deadLetterTopic, "Dead-letter padrão deveria ser <tópico>.dlq")
+
+	mainTopic := "orders"
+	retryTopic := "orders.retry.10m"
+	assert.Equal(t, 0, tiers.level(&kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &mainTopic}}))
+	assert.Equal(t, 3, tiers.level(&kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &retryTopic}}))
+
+	assert.Nil(t, newRetryTiers("orders", nil, ""), "Sem atrasos não deveria haver níveis de retentativa")
+}
+
+// Teste das coordenadas de origem ao longo dos tópicos de retentativa
+// A partição e o offset do tópico principal acompanham a mensagem em cada nível e chegam à dead-letter,
+// junto com o tópico principal, em vez das coordenadas do tópico de retentativa.
+func TestRetryTiersCarryOriginalPosition(t *testing.T) {
+	cluster, err := kafka.NewMockCluster(1)
+	require.NoError(t, err)
+	defer cluster.Close()
+	for _, name := range []string{"orders.retry.1m", "orders.retry.10m", "orders.dlq"} {
+		require.NoError(t, cluster.CreateTopic(name, 1, 1))
+	}
+
+	producer, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": cluster.BootstrapServers()})
+	require.NoError(t, err)
+	defer producer.Close()
+
+	reader, err := kafka.NewConsumer(&kafka.ConfigMap{"bootstrap.servers": cluster.BootstrapServers(), "group.id": "retry-test"})
+	require.NoError(t, err)
+	defer reader.Close()
+	read := func(topic string) *kafka.Message {
+		require.NoError(t, reader.Assign([]kafka.TopicPartition{{Topic: &topic, Partition: 0, Offset: kafka.OffsetBeginning}}))
+		record, err := reader.ReadMessage(10 * time.Second)
+		require.NoError(t, err)
+		return record
+	}
+	headers := func(record *kafka.Message) map[string]string {
+		values := make(map[string]string, len(record.Headers))
+		for _, header := range record.Headers {
+			values[header.Key] = string(header.Value)
+		}
+		return values
+	}
+
+	target := forwarder{ctx: context.Background(), producer: producer}
+	tiers := newRetryTiers("orders", []time.Duration{time.Minute, 10 * time.Minute}, "")
+	topic := "orders"
+	record := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 2, Offset: 41}, Value: []byte("pedido")}
+
+	require.NoError(t, forwardToRetryTier(target, tiers, 1, record, errors.New("falha")))
+	first := read("orders.retry.1m")
+	assert.Equal(t, "2", headers(first)[RetryHeaderOriginalPartition])
+	assert.Equal(t, "41", headers(first)[RetryHeaderOriginalOffset])
+
+	require.NoError(t, forwardToRetryTier(target, tiers, 2, first, errors.New("falha")))
+	second := read("orders.retry.10m")
+	assert.Equal(t, "2", headers(second)[RetryHeaderOriginalPartition], "O segundo nível deveria manter a partição do tópico principal")
+	assert.Equal(t, "41", headers(second)[RetryHeaderOriginalOffset], "O segundo nível deveria manter o offset do tópico principal")
+
+	require.NoError(t, forwardToDeadLetter(target, tiers.deadLetterTopic, second, errors.New("falha"), 3))
+	deadLetter := headers(read("orders.dlq"))
+	assert.Equal(t, "orders", deadLetter[DeadLetterHeaderTopic])
+	assert.Equal(t, "2", deadLetter[DeadLetterHeaderPartition])
+	assert.Equal(t, "41", deadLetter[DeadLetterHeaderOffset])
+}
+
+// Teste do consumo dos tópicos de retentativa por consumidores próprios
+// O handler da assinatura principal fica bloqueado até a mensagem falha ser tratada no tópico de retentativa,
+// o que só termina porque o nível é consumido por outro cliente, com o seu próprio laço de consumo.
+func TestConsumeRetryTierWithOwnConsumer(t *testing.T) {
+	cluster, err := kafka.NewMockCluster(1)
+	require.NoError(t, err)
+	defer cluster.Close()
+	require.NoError(t, cluster.CreateTopic("orders", 1, 1))
+	require.NoError(t, cluster.CreateTopic("orders.retry.100ms", 1, 1))
+
+	producer, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": cluster.BootstrapServers()})
+	require.NoError(t, err)
+	defer producer.Close()
+
+	topic := "orders"
+	deliveries := make(chan kafka.Event, 2)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, producer.Produce(&kafka.Message{
+			TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 0},
+			Value:          []byte(fmt.Sprintf("%q", fmt.Sprint(i))),
+		}, deliveries))
+	}
+	for i := 0; i < 2; i++ {
+		require.NoError(t, (<-deliveries).(*kafka.Message).TopicPartition.Error)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &kafkaConsumer[string]{ctx: ctx, logger: logger.NewNopLogger(), producer: producer, consumerSetup: mockConsumerSetup{servers: cluster.BootstrapServers()}}
+	c.initializeDeserializers()
+
+	var retried []string
+	handled := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Consume("orders", enums.JsonDeserialization, enums.OnDeserializationFailedStopHost,
+			ConsumeOptions{GroupId: "orders-group", RetryTopicDelays: []time.Duration{100 * time.Millisecond}},
+			func(msg message.Message[string]) error {
+				switch {
+				case msg.Topic == "orders.retry.100ms":
+					retried = append(retried, msg.Data)
+					close(handled)
+					return nil
+				case msg.Data == "0":
+					return errors.New("falha")
+				default:
+					// A assinatura principal não avança até o nível de retentativa tratar a mensagem falha
+					select {
+					case <-handled:
+						return nil
+					case <-time.After(10 * time.Second):
+						return errors.New("mensagem de retentativa não tratada")
+					}
+				}
+			})
+	}()
+
+	select {
+	case <-handled:
+		assert.Equal(t, []string{"0"}, retried, "A mensagem falha deveria ser tratada no tópico de retentativa")
+	case <-time.After(15 * time.Second):
+		t.Fatal("A mensagem de retentativa não foi tratada enquanto a assinatura principal estava ocupada")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+}
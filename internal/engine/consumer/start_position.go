@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"time"
+
+	internalEnums "github.com/Dieg657/kafka-toolkit-lib/internal/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// StartPosition define onde a assinatura começa a consumir cada partição, ignorando o offset
+// confirmado do grupo. É aplicada uma única vez por partição, na primeira atribuição; em
+// rebalanceamentos seguintes o consumo continua do offset confirmado.
+//
+// Offsets explícitos têm precedência sobre Timestamp, que tem precedência sobre Reset.
+type StartPosition struct {
+	// Offsets define o offset inicial de partições específicas
+	Offsets []kafka.TopicPartition
+	// Timestamp inicia cada partição no primeiro offset com timestamp maior ou igual ao informado
+	Timestamp time.Time
+	// Reset inicia cada partição pelo início (BEGINNING, EARLIEST, SMALLEST) ou pelo fim (END, LATEST, LARGEST)
+	Reset internalEnums.AutoOffsetReset
+}
+
+// offsetsForTimesTimeout é o tempo máximo da consulta de offsets por timestamp
+const offsetsForTimesTimeout = 10000
+
+// Mapa dos valores de AutoOffsetReset para os offsets lógicos do Kafka
+var logicalOffsets = map[internalEnums.AutoOffsetReset]kafka.Offset{
+	internalEnums.OFFSET_RESET_BEGINNING: kafka.OffsetBeginning,
+	internalEnums.OFFSET_RESET_EARLIEST:  kafka.OffsetBeginning,
+	internalEnums.OFFSET_RESET_SMALLEST:  kafka.OffsetBeginning,
+	internalEnums.OFFSET_RESET_END:       kafka.OffsetEnd,
+	internalEnums.OFFSET_RESET_LATEST:    kafka.OffsetEnd,
+	internalEnums.OFFSET_RESET_LARGEST:   kafka.OffsetEnd,
+}
+
+// startPositions aplica a posição inicial às partições atribuídas pela primeira vez
+type startPositions struct {
+	position StartPosition
+	explicit map[partitionKey]kafka.Offset
+	applied  map[partitionKey]bool
+	logger   logger.ILogger
+}
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// newStartPositions prepara a posição inicial informada. Retorna nil quando nenhuma foi configurada.
+func newStartPositions(position StartPosition, log logger.ILogger) *startPositions {
+	if len(position.Offsets) == 0 && position.Timestamp.IsZero() && position.Reset == "" {
+		return nil
+	}
+
+	start := &startPositions{
+		position: position,
+		explicit: make(map[partitionKey]kafka.Offset, len(position.Offsets)),
+		applied:  make(map[partitionKey]bool),
+		logger:   log,
+	}
+	for _, tp := range position.Offsets {
+		start.explicit[keyOf(tp)] = tp.Offset
+	}
+	return start
+}
+
+// ==========================================================================
+// Métodos
+// ==========================================================================
+
+// apply retorna as partições atribuídas com o offset inicial definido para as que ainda não
+// foram iniciadas. Partições sem posição configurada, e as excluídas por skip, mantêm o offset
+// confirmado do grupo.
+func (s *startPositions) apply(client *kafka.Consumer, partitions []kafka.TopicPartition, skip func(topic string) bool) []kafka.TopicPartition {
+	if s == nil {
+		return partitions
+	}
+
+	result := make([]kafka.TopicPartition, len(partitions))
+	copy(result, partitions)
+
+	var byTime []int
+	for i, tp := range result {
+		key := keyOf(tp)
+		if s.applied[key] {
+			continue
+		}
+		s.applied[key] = true
+		if skip(key.topic) {
+			continue
+		}
+
+		if offset, exists := s.explicit[key]; exists {
+			result[i].Offset = offset
+			continue
+		}
+		if !s.position.Timestamp.IsZero() {
+			byTime = append(byTime, i)
+			continue
+		}
+		if offset, exists := logicalOffsets[s.position.Reset]; exists {
+			result[i].Offset = offset
+		}
+	}
+
+	if len(byTime) > 0 {
+		s.resolveTimestamp(client, result, byTime)
+	}
+
+	for _, tp := range result {
+		if tp.Offset != kafka.OffsetInvalid {
+			s.logger.Info("Partition start position set", partitionFields(tp)...)
+		}
+	}
+	return result
+}
+
+// resolveTimestamp consulta, com OffsetsForTimes, o primeiro offset de cada partição com timestamp
+// maior ou igual ao configurado. Partições sem mensagens posteriores ao timestamp iniciam pelo fim.
+func (s *startPositions) resolveTimestamp(client *kafka.Consumer, partitions []kafka.TopicPartition, indexes []int) {
+	query := make([]kafka.TopicPartition, 0, len(indexes))
+	for _, i := range indexes {
+		tp := partitions[i]
+		tp.Offset = kafka.Offset(s.position.Timestamp.UnixMilli())
+		query = append(query, tp)
+	}
+
+	offsets, err := client.OffsetsForTimes(query, offsetsForTimesTimeout)
+	if err != nil {
+		s.logger.Error("Error on query offsets for timestamp, using committed offsets", "timestamp", s.position.Timestamp, logger.FieldError, err)
+		return
+	}
+
+	resolved := make(map[partitionKey]kafka.TopicPartition, len(offsets))
+	for _, tp := range offsets {
+		resolved[keyOf(tp)] = tp
+	}
+
+	for _, i := range indexes {
+		tp, exists := resolved[keyOf(partitions[i])]
+		switch {
+		case !exists || tp.Error != nil:
+			s.logger.Error("Error on query offset for timestamp, using committed offset", partitionFields(partitions[i], logger.FieldError, tp.Error)...)
+		case tp.Offset < 0:
+			partitions[i].Offset = kafka.OffsetEnd
+		default:
+			partitions[i].Offset = tp.Offset
+		}
+	}
+}
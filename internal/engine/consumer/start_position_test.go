@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"testing"
+
+	internalEnums "github.com/Dieg657/kafka-toolkit-lib/internal/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+// Teste da posição inicial das partições
+// Offsets explícitos prevalecem sobre o reset, a posição vale apenas na primeira atribuição
+// e os tópicos excluídos mantêm o offset confirmado.
+func TestStartPositionsAppliedOnFirstAssignment(t *testing.T) {
+	orders, retry := "orders", "orders.retry.1m"
+	tp := func(topic *string, partition int32) kafka.TopicPartition {
+		return kafka.TopicPartition{Topic: topic, Partition: partition, Offset: kafka.OffsetInvalid}
+	}
+	skip := func(topic string) bool { return topic == retry }
+
+	assert.Nil(t, newStartPositions(StartPosition{}, logger.NewNopLogger()), "Sem posição configurada não deveria haver ajuste")
+
+	start := newStartPositions(StartPosition{
+		Offsets: []kafka.TopicPartition{{Topic: &orders, Partition: 1, Offset: 42}},
+		Reset:   internalEnums.OFFSET_RESET_BEGINNING,
+	}, logger.NewNopLogger())
+
+	partitions := start.apply(nil, []kafka.TopicPartition{tp(&orders, 0), tp(&orders, 1), tp(&retry, 0)}, skip)
+	assert.Equal(t, kafka.OffsetBeginning, partitions[0].Offset)
+	assert.Equal(t, kafka.Offset(42), partitions[1].Offset)
+	assert.Equal(t, kafka.OffsetInvalid, partitions[2].Offset, "Tópicos de retentativa deveriam manter o offset confirmado")
+
+	again := start.apply(nil, []kafka.TopicPartition{tp(&orders, 0)}, skip)
+	assert.Equal(t, kafka.OffsetInvalid, again[0].Offset, "A posição inicial deveria valer apenas na primeira atribuição")
+
+	end := newStartPositions(StartPosition{Reset: internalEnums.OFFSET_RESET_LATEST}, logger.NewNopLogger())
+	assert.Equal(t, kafka.OffsetEnd, end.apply(nil, []kafka.TopicPartition{tp(&orders, 0)}, skip)[0].Offset)
+}
@@ -0,0 +1,323 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// subscription reúne o estado de uma assinatura em execução:
+// o tópico principal, os demais tópicos assinados, as opções informadas e as estruturas derivadas delas.
+// Cada nível de retentativa é uma assinatura própria dos tópicos do nível, criada por retrySubscriptions.
+type subscription struct {
+	topic         string
+	subscribed    []string
+	groupSuffix   string // Sufixo do grupo dos consumidores de retentativa ("" na assinatura principal)
+	readCommitted bool   // Força isolation.level=read_committed (consumo transacional)
+	options       ConsumeOptions
+	tiers         []*retryTiers
+	tiersByTopic  map[string]*retryTiers
+	delayed       *delayedPartitions
+	flow          *flowControl
+	start         *startPositions
+	ends          *endOffsets
+	// onRevoke libera o estado das partições revogadas e retorna os offsets processados a confirmar
+	onRevoke func(partitions []kafka.TopicPartition) []kafka.TopicPartition
+}
+
+// topicPatternPrefix identifica uma assinatura por expressão regular, como no librdkafka
+const topicPatternPrefix = "^"
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// newSubscription prepara o estado de uma assinatura do tópico principal e dos tópicos adicionais
+// das opções. Tópicos iniciados por "^" são expressões regulares resolvidas pelo librdkafka.
+func newSubscription(topic string, options ConsumeOptions, log logger.ILogger) (*subscription, error) {
+	sub := &subscription{
+		topic:        topic,
+		options:      options,
+		tiersByTopic: make(map[string]*retryTiers),
+		delayed:      newDelayedPartitions(log),
+		flow:         newFlowControl(log),
+		start:        newStartPositions(options.StartPosition, log),
+		ends:         newEndOffsets(options.Bounded, log),
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range append([]string{topic}, options.Topics...) {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		sub.subscribed = append(sub.subscribed, name)
+	}
+
+	if len(sub.subscribed) == 0 {
+		return nil, errors.New("nenhum tópico informado para a assinatura")
+	}
+
+	if sub.options.CommitMode == "" {
+		sub.options.CommitMode = enums.CommitPeriodic
+	}
+	if sub.options.CommitMode == enums.CommitManual {
+		if options.Controller == nil {
+			return nil, errors.New("commit manual exige um Controller para confirmar os offsets")
+		}
+		if sub.manual() && options.GroupId == "" {
+			return nil, errors.New("commit manual com partições atribuídas manualmente exige um grupo de consumidores")
+		}
+		options.Controller.enableCommits()
+	}
+
+	if sub.manual() {
+		// A atribuição manual exige conhecer as partições de cada tópico
+		for _, name := range sub.subscribed {
+			if isTopicPattern(name) {
+				return nil, errors.New("partições atribuídas manualmente não podem ser usadas com assinatura por expressão regular")
+			}
+		}
+		if len(options.RetryTopicDelays) > 0 {
+			return nil, errors.New("tópicos de retentativa não podem ser usados com partições atribuídas manualmente")
+		}
+	}
+
+	if len(options.RetryTopicDelays) == 0 {
+		return sub, nil
+	}
+
+	// Cada atraso nomeia um tópico e um grupo de consumidores próprios, que não podem se repetir
+	suffixes := make(map[string]bool)
+	for _, delay := range options.RetryTopicDelays {
+		if delay <= 0 {
+			return nil, fmt.Errorf("atraso de retentativa inválido: %s (deve ser positivo)", delay)
+		}
+		suffix := retrySuffix(delay)
+		if suffixes[suffix] {
+			return nil, fmt.Errorf("atraso de retentativa repetido: %s (tópico %s)", delay, RetryTopicName(topic, delay))
+		}
+		suffixes[suffix] = true
+	}
+
+	// Os tópicos de retentativa são derivados do nome de cada tópico assinado
+	for _, name := range sub.subscribed {
+		if isTopicPattern(name) {
+			return nil, errors.New("tópicos de retentativa não podem ser usados com assinatura por expressão regular")
+		}
+	}
+
+	for _, name := range sub.subscribed {
+		tiers := newRetryTiers(name, options.RetryTopicDelays, options.DeadLetterTopic)
+		sub.tiers = append(sub.tiers, tiers)
+		for _, retryTopic := range tiers.subscriptionTopics() {
+			sub.tiersByTopic[retryTopic] = tiers
+		}
+	}
+
+	return sub, nil
+}
+
+// ==========================================================================
+// Métodos
+// ==========================================================================
+
+// manual indica se as partições são atribuídas diretamente, sem participar do grupo de consumidores
+func (s *subscription) manual() bool {
+	return len(s.options.Partitions) > 0
+}
+
+// partitions retorna as partições atribuídas manualmente de cada tópico, a partir do offset confirmado
+func (s *subscription) partitions() []kafka.TopicPartition {
+	partitions := make([]kafka.TopicPartition, 0, len(s.subscribed)*len(s.options.Partitions))
+	for _, name := range s.subscribed {
+		for _, partition := range s.options.Partitions {
+			topic := name
+			partitions = append(partitions, kafka.TopicPartition{Topic: &topic, Partition: partition, Offset: kafka.OffsetStored})
+		}
+	}
+	return partitions
+}
+
+// topics retorna os tópicos assinados. Os tópicos de retentativa são assinados pelos consumidores de cada nível.
+func (s *subscription) topics() []string {
+	return s.subscribed
+}
+
+// retrySubscriptions retorna uma assinatura para cada nível de retentativa, com os tópicos do nível de todos os
+// tópicos assinados. Cada nível é consumido por um cliente próprio, em um grupo derivado do grupo da assinatura
+// ("<grupo>.retry.<atraso>"), com seus próprios rebalanceamentos e max.poll.interval.ms. Os níveis começam do
+// início dos tópicos quando o grupo ainda não tem offsets confirmados e não usam o Controller, os hooks de
+// rebalanceamento, a posição inicial nem o consumo limitado da assinatura principal: as mensagens tratadas são
+// confirmadas automaticamente, mesmo no commit manual.
+func (s *subscription) retrySubscriptions(log logger.ILogger) []*subscription {
+	if len(s.tiers) == 0 {
+		return nil
+	}
+
+	options := s.options
+	options.Topics = nil
+	options.Bounded = false
+	options.AutoOffsetReset = enums.OffsetResetEarliest
+	options.StartPosition = StartPosition{}
+	options.Rebalance = RebalanceHooks{}
+	options.Controller = nil
+	if options.CommitMode == enums.CommitManual {
+		options.CommitMode = enums.CommitPerMessage
+	}
+
+	subs := make([]*subscription, 0, len(options.RetryTopicDelays))
+	for level, delay := range options.RetryTopicDelays {
+		sub := &subscription{
+			topic:        s.topic,
+			groupSuffix:  retrySuffix(delay),
+			options:      options,
+			tiers:        s.tiers,
+			tiersByTopic: s.tiersByTopic,
+			delayed:      newDelayedPartitions(log),
+			flow:         newFlowControl(log),
+			start:        newStartPositions(options.StartPosition, log),
+			ends:         newEndOffsets(false, log),
+		}
+		for _, tiers := range s.tiers {
+			sub.subscribed = append(sub.subscribed, tiers.topics[level])
+		}
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// tiersOf retorna os níveis de retentativa do tópico de origem da mensagem, ou nil se não houver
+func (s *subscription) tiersOf(record *kafka.Message) *retryTiers {
+	return s.tiersByTopic[topicOf(record)]
+}
+
+// sourceTopic retorna o tópico de origem da mensagem.
+// Mensagens dos tópicos de retentativa pertencem ao tópico de onde vieram.
+func (s *subscription) sourceTopic(record *kafka.Message) string {
+	if tiers := s.tiersOf(record); tiers != nil {
+		return tiers.topic
+	}
+	return topicOf(record)
+}
+
+// deserializationTopic retorna o tópico usado na resolução de subject do Schema Registry:
+// o tópico real do registro ou, para os tópicos de retentativa, o tópico onde o schema foi registrado.
+func (s *subscription) deserializationTopic(record *kafka.Message) string {
+	return s.sourceTopic(record)
+}
+
+// deadLetterTopic retorna o tópico de dead-letter configurado ou, se ausente, "<tópico de origem>.dlq"
+func (s *subscription) deadLetterTopic(record *kafka.Message) string {
+	if s.options.DeadLetterTopic != "" {
+		return s.options.DeadLetterTopic
+	}
+	return s.sourceTopic(record) + ".dlq"
+}
+
+// hold indica se a mensagem de retentativa ainda não venceu, pausando sua partição até o vencimento
+func (s *subscription) hold(client *kafka.Consumer, record *kafka.Message) bool {
+	tiers := s.tiersOf(record)
+	if tiers == nil || tiers.level(record) == 0 {
+		return false
+	}
+	return s.delayed.hold(client, record)
+}
+
+// saturate pausa a partição da mensagem por saturationDelay, reposicionando o consumo na própria mensagem,
+// enquanto a fila do worker responsável por ela estiver cheia. Retorna false quando a partição não pôde ser pausada.
+func (s *subscription) saturate(client *kafka.Consumer, record *kafka.Message) bool {
+	return s.delayed.delay(client, record.TopicPartition, time.Now().Add(saturationDelay))
+}
+
+// release libera o estado das partições revogadas, retornando os offsets processados a confirmar
+func (s *subscription) release(partitions []kafka.TopicPartition) []kafka.TopicPartition {
+	s.delayed.forget(partitions)
+	s.flow.revoked(partitions)
+	s.ends.revoke(partitions)
+	if s.onRevoke == nil {
+		return nil
+	}
+	return s.onRevoke(partitions)
+}
+
+// resumeDue retoma as partições de retentativa cujo prazo venceu e que não foram pausadas pela aplicação,
+// por backpressure ou por terem alcançado o offset final
+func (s *subscription) resumeDue(client *kafka.Consumer) {
+	s.delayed.resumeDue(client, func(key partitionKey) bool {
+		return s.flow.blocked(key) || s.ends.isReached(key)
+	})
+}
+
+// assigned pausa as partições recém-atribuídas quando a assinatura está pausada
+// e, no consumo limitado, registra o offset final de cada uma
+func (s *subscription) assigned(client *kafka.Consumer, partitions []kafka.TopicPartition) {
+	s.flow.assigned(client, partitions)
+	s.ends.assign(client, partitions)
+}
+
+// held indica se a partição está pausada por outro mecanismo que não o controle de fluxo:
+// aguardando uma mensagem de retentativa ou encerrada no consumo limitado
+func (s *subscription) held(key partitionKey) bool {
+	return s.delayed.holding(key) || s.ends.isReached(key)
+}
+
+// beyond indica, no consumo limitado, se a mensagem está além do offset final da partição e deve ser ignorada
+func (s *subscription) beyond(client *kafka.Consumer, record *kafka.Message) bool {
+	return s.ends.beyond(client, record)
+}
+
+// processed registra, no consumo limitado, a mensagem concluída da partição
+func (s *subscription) processed(client *kafka.Consumer, tp kafka.TopicPartition) {
+	s.ends.advance(client, tp)
+}
+
+// endOfPartition registra, no consumo limitado, o fim de partição sinalizado pelo librdkafka
+func (s *subscription) endOfPartition(client *kafka.Consumer, eof kafka.PartitionEOF) {
+	s.ends.endOfPartition(client, eof)
+}
+
+// finished indica, no consumo limitado, se todas as partições atribuídas alcançaram o offset final
+func (s *subscription) finished(client *kafka.Consumer) bool {
+	return s.ends.finished(client)
+}
+
+// startAt aplica a posição inicial configurada às partições atribuídas pela primeira vez.
+// Os tópicos de retentativa sempre continuam do offset confirmado.
+func (s *subscription) startAt(client *kafka.Consumer, partitions []kafka.TopicPartition) []kafka.TopicPartition {
+	return s.start.apply(client, partitions, s.isRetryTopic)
+}
+
+// isRetryTopic indica se o tópico é um dos tópicos de retentativa da assinatura
+func (s *subscription) isRetryTopic(topic string) bool {
+	tiers := s.tiersByTopic[topic]
+	return tiers != nil && tiers.levels[topic] > 0
+}
+
+// command executa uma operação recebida do Controller
+func (s *subscription) command(client *kafka.Consumer, command controlCommand) {
+	command.result <- command.apply(client, s)
+}
+
+// pressure aplica o backpressure de acordo com o número de mensagens em processamento
+func (s *subscription) pressure(client *kafka.Consumer, inFlight int) {
+	s.flow.pressure(client, inFlight, s.held)
+}
+
+// ==========================================================================
+// Funções
+// ==========================================================================
+
+// isTopicPattern indica se o tópico é uma expressão regular no formato do librdkafka
+func isTopicPattern(topic string) bool {
+	return strings.HasPrefix(topic, topicPatternPrefix)
+}
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Teste da assinatura de vários tópicos e expressões regulares
+func TestSubscriptionMultipleTopicsAndPatterns(t *testing.T) {
+	sub, err := newSubscription("orders", ConsumeOptions{Topics: []string{`^audit\..*`, "payments", "orders"}}, logger.NewNopLogger())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"orders", `^audit\..*`, "payments"}, sub.topics(), "Tópicos repetidos deveriam ser ignorados")
+
+	matched := "audit.users"
+	record := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &matched}}
+	assert.Equal(t, "audit.users", sub.deserializationTopic(record), "Subject deveria ser resolvido pelo tópico real da mensagem")
+	assert.Equal(t, "audit.users.dlq", sub.deadLetterTopic(record))
+
+	_, err = newSubscription(`^audit\..*`, ConsumeOptions{RetryTopicDelays: []time.Duration{time.Minute}}, logger.NewNopLogger())
+	assert.Error(t, err, "Tópicos de retentativa não deveriam aceitar expressões regulares")
+}
+
+// Teste dos tópicos de retentativa de uma assinatura com vários tópicos
+func TestSubscriptionRetryTiersPerTopic(t *testing.T) {
+	sub, err := newSubscription("orders", ConsumeOptions{Topics: []string{"payments"}, RetryTopicDelays: []time.Duration{time.Minute}}, logger.NewNopLogger())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"orders", "payments"}, sub.topics(), "Tópicos de retentativa deveriam ter consumidores próprios")
+
+	retryTopic := "payments.retry.1m"
+	record := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &retryTopic}}
+	assert.Equal(t, "payments", sub.deserializationTopic(record), "Mensagens de retentativa deveriam usar o tópico de origem")
+	assert.Equal(t, "payments.dlq", sub.deadLetterTopic(record))
+	assert.Equal(t, 1, sub.tiersOf(record).level(record))
+}
+
+// Teste da validação dos atrasos dos tópicos de retentativa
+// Atrasos não positivos ou repetidos, que gerariam o mesmo tópico e o mesmo grupo, são rejeitados.
+func TestSubscriptionRejectsInvalidRetryDelays(t *testing.T) {
+	for name, delays := range map[string][]time.Duration{
+		"zero":     {0},
+		"negativo": {time.Minute, -time.Second},
+		"repetido": {time.Minute, 60 * time.Second},
+	} {
+		_, err := newSubscription("orders", ConsumeOptions{RetryTopicDelays: delays}, logger.NewNopLogger())
+		assert.Error(t, err, name)
+	}
+
+	_, err := newSubscription("orders", ConsumeOptions{RetryTopicDelays: []time.Duration{time.Minute, 10 * time.Minute}}, logger.NewNopLogger())
+	assert.NoError(t, err)
+}
+
+// Teste das assinaturas dos consumidores de cada nível de retentativa
+// Cada nível assina os seus tópicos com um grupo próprio, começando do início quando não houver offset confirmado.
+func TestSubscriptionRetrySubscriptionsPerTier(t *testing.T) {
+	sub, err := newSubscription("orders", ConsumeOptions{
+		Topics:           []string{"payments"},
+		RetryTopicDelays: []time.Duration{time.Minute, time.Hour},
+		CommitMode:       enums.CommitManual,
+		Controller:       NewController(),
+		Bounded:          true,
+	}, logger.NewNopLogger())
+	require.NoError(t, err)
+
+	subs := sub.retrySubscriptions(logger.NewNopLogger())
+	require.Len(t, subs, 2)
+
+	assert.Equal(t, []string{"orders.retry.1m", "payments.retry.1m"}, subs[0].topics())
+	assert.Equal(t, ".retry.1m", subs[0].groupSuffix)
+	assert.Equal(t, []string{"orders.retry.1h", "payments.retry.1h"}, subs[1].topics())
+	assert.Equal(t, ".retry.1h", subs[1].groupSuffix)
+
+	for _, tierSub := range subs {
+		assert.Equal(t, enums.OffsetResetEarliest, tierSub.options.AutoOffsetReset)
+		assert.Equal(t, enums.CommitPerMessage, tierSub.options.CommitMode, "O commit manual pertence apenas à assinatura principal")
+		assert.False(t, tierSub.options.Bounded)
+		assert.Nil(t, tierSub.options.Controller, "O Controller pertence apenas à assinatura principal")
+	}
+
+	plain, err := newSubscription("orders", ConsumeOptions{}, logger.NewNopLogger())
+	require.NoError(t, err)
+	assert.Empty(t, plain.retrySubscriptions(logger.NewNopLogger()))
+}
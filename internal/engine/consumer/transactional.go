@@ -0,0 +1,265 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	producerEngine "github.com/Dieg657/kafka-toolkit-lib/internal/engine/producer"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/constants"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/ioc"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades Estáticas
+// ==========================================================================
+
+// transactionTimeout limita a confirmação e o aborto de uma transação, inclusive durante o encerramento
+const transactionTimeout = 30 * time.Second
+
+// TransactionalHandler processa uma mensagem dentro de uma transação.
+// As publicações feitas na transação e o offset da mensagem são confirmados juntos quando o handler retorna nil.
+type TransactionalHandler[TData any] func(tx *producerEngine.Transaction, message message.Message[TData]) error
+
+// ==========================================================================
+// Métodos Públicos
+// ==========================================================================
+
+// ConsumeTransactionally consome mensagens de um tópico no padrão consume-transform-produce com exactly-once.
+// Para cada mensagem é iniciada uma transação no produtor transacional; as publicações do handler e o
+// offset da mensagem, associado aos metadados do grupo de consumidores, são confirmados atomicamente.
+// Em caso de erro a transação é abortada e o handler repetido conforme a RetryPolicy; esgotadas as
+// tentativas a mensagem é publicada na dead-letter (padrão: "<tópico>.dlq") na mesma transação que confirma
+// o seu offset. Tópicos de retentativa não são suportados neste modo. Um erro fatal do produtor
+// transacional encerra o consumo com ErrTransactionalProducerFatal, sem confirmar a mensagem. O consumidor
+// usa sempre isolation.level=read_committed, qualquer que seja a prioridade.
+//
+// Parâmetros:
+//   - topic: Nome do tópico Kafka a ser consumido
+//   - deserialization: Formato de deserialização das mensagens
+//   - strategy: Estratégia aplicada quando a deserialização falha
+//   - options: Opções de retentativa e dead-letter
+//   - handler: Função chamada com a transação e a mensagem
+//
+// Retorno:
+//   - error: Erro que encerrou o consumo
+func (c *kafkaConsumer[TData]) ConsumeTransactionally(topic string, deserialization enums.Deserialization, strategy enums.DeserializationStrategy, options ConsumeOptions, handler TransactionalHandler[TData]) (consumeErr error) {
+	if len(options.Partitions) > 0 && options.GroupId == "" {
+		return errors.New("consumo transacional com partições atribuídas manualmente exige um grupo de consumidores")
+	}
+
+	container, _ := c.ctx.Value(constants.IocKey).(ioc.IContainer)
+	if container == nil {
+		return errors.New("IoC do Kafka não encontrado no contexto")
+	}
+	if producerSetup, _ := container.GetTransactionalProducer(); producerSetup == nil {
+		return errors.New("produtor transacional não configurado: informe KAFKA_TRANSACTIONAL_ID")
+	}
+
+	if err := options.Controller.attach(); err != nil {
+		return err
+	}
+	defer func() { options.Controller.finish(consumeErr) }()
+	stop := options.Controller.stopping()
+	commands := options.Controller.control()
+
+	options.RetryTopicDelays = nil
+	// Os offsets são confirmados nas transações de cada mensagem, nunca pelo librdkafka
+	options.CommitMode = enums.CommitPerMessage
+	sub, err := newSubscription(topic, options, c.logger)
+	if err != nil {
+		return err
+	}
+	// Apenas mensagens de transações confirmadas são transformadas, mesmo nas prioridades read_uncommitted
+	sub.readCommitted = true
+
+	if err := c.open(sub); err != nil {
+		return err
+	}
+
+	err = c.subscribe(sub)
+	if err != nil {
+		c.close()
+		return err
+	}
+
+	run := true
+
+	for run {
+		select {
+		case <-stop:
+			c.logger.Info("Terminating consumer: close requested", logger.FieldTopic, topic)
+			run = false
+		case <-c.ctx.Done():
+			c.logger.Info("Terminating consumer: context done", logger.FieldTopic, topic)
+			run = false
+		case command := <-commands:
+			sub.command(c.client, command)
+		default:
+			if sub.finished(c.client) {
+				c.logger.Info("Terminating consumer: end offsets reached", logger.FieldTopic, topic)
+				run = false
+				continue
+			}
+
+			ev := c.client.Poll(100)
+			if ev == nil {
+				continue
+			}
+			switch e := ev.(type) {
+			case *kafka.Message:
+				if sub.beyond(c.client, e) {
+					continue
+				}
+
+				baseMessage, decoded, err := c.prepareMessage(sub, e, deserialization, strategy)
+				if err != nil {
+					consumeErr = err
+					run = false
+					continue
+				}
+
+				if decoded {
+					err = c.handleInTransaction(sub, e, baseMessage, handler)
+				} else {
+					err = c.runTransaction(e, nil)
+				}
+
+				if errors.Is(err, producerEngine.ErrTransactionalProducerFatal) {
+					// O produtor transacional não aceita novas transações: o consumo é encerrado sem confirmar a mensagem
+					consumeErr = err
+					run = false
+					continue
+				}
+				if err != nil {
+					// Nada foi confirmado: a mensagem volta a ser consumida
+					c.logger.Error("Error on transactional handle message", recordFields(e, logger.FieldError, err)...)
+					c.rewind(e.TopicPartition)
+					continue
+				}
+				sub.processed(c.client, e.TopicPartition)
+			case kafka.PartitionEOF:
+				sub.endOfPartition(c.client, e)
+			case kafka.Error:
+				c.logger.Error("Kafka client error", logger.FieldTopic, topic, "code", e.Code().String(), logger.FieldError, e)
+			default:
+				c.logger.Debug("Ignored event", logger.FieldTopic, topic, "event", e.String())
+			}
+		}
+	}
+
+	// Os offsets são confirmados nas transações de cada mensagem
+	c.close()
+	return consumeErr
+}
+
+// ==========================================================================
+// Métodos Privados
+// ==========================================================================
+
+// handleInTransaction executa o handler em uma transação, repetindo-o em novas transações conforme a RetryPolicy.
+// Esgotadas as tentativas, encaminha a mensagem para a dead-letter em uma transação que confirma apenas o seu offset.
+// Duplicatas identificadas pelo store de deduplicação têm apenas o offset confirmado, sem chamar o handler.
+func (c *kafkaConsumer[TData]) handleInTransaction(sub *subscription, record *kafka.Message, baseMessage message.Message[TData], handler TransactionalHandler[TData]) error {
+	attempts := 0
+
+	key, seen, err := c.duplicate(sub, record)
+	if err != nil {
+		return err
+	}
+	if seen {
+		return c.runTransaction(record, nil)
+	}
+
+	for {
+		attempts++
+		err := c.runTransaction(record, func(tx *producerEngine.Transaction) error {
+			return c.intercept(sub, record, baseMessage, func(message message.Message[TData]) error {
+				return handler(tx, message)
+			})
+		})
+		if err == nil {
+			// A chave é gravada somente após a confirmação da transação
+			c.remember(sub, record, key)
+			return nil
+		}
+		if errors.Is(err, producerEngine.ErrTransactionalProducerFatal) {
+			return err
+		}
+
+		if attempts > sub.options.RetryPolicy.MaxRetries {
+			return c.deadLetterInTransaction(sub, record, err, attempts)
+		}
+
+		c.logger.Warn("Error on transactional handle message, retrying", recordFields(record, "attempt", attempts, logger.FieldError, err)...)
+
+		select {
+		case <-c.ctx.Done():
+			return c.ctx.Err()
+		case <-time.After(sub.options.RetryPolicy.Backoff(attempts)):
+		}
+	}
+}
+
+// deadLetterInTransaction publica a mensagem na dead-letter e confirma o seu offset na mesma transação:
+// ou a mensagem é encaminhada e confirmada, ou nada é confirmado e ela volta a ser consumida
+func (c *kafkaConsumer[TData]) deadLetterInTransaction(sub *subscription, record *kafka.Message, cause error, attempts int) error {
+	deadLetterTopic := sub.deadLetterTopic(record)
+	err := c.runTransaction(record, func(tx *producerEngine.Transaction) error {
+		return producerEngine.ProduceInTransaction(tx, deadLetterRecord(deadLetterTopic, record, cause, attempts))
+	})
+	if err != nil {
+		return err
+	}
+
+	c.logger.Warn("Message forwarded to dead-letter topic", recordFields(record, "deadLetterTopic", deadLetterTopic, "attempts", attempts, logger.FieldError, cause)...)
+	return nil
+}
+
+// runTransaction executa work em uma nova transação e inclui nela o offset seguinte ao registro.
+// Com work nil apenas o offset é confirmado. Qualquer erro aborta a transação.
+func (c *kafkaConsumer[TData]) runTransaction(record *kafka.Message, work func(tx *producerEngine.Transaction) error) error {
+	tx, err := producerEngine.BeginTransaction(c.ctx)
+	if err != nil {
+		return err
+	}
+
+	// A confirmação deve concluir mesmo que o contexto do consumidor seja cancelado
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.ctx), transactionTimeout)
+	defer cancel()
+
+	err = c.transact(ctx, tx, record, work)
+	if err != nil {
+		if abortErr := tx.Abort(ctx); abortErr != nil && !errors.Is(abortErr, producerEngine.ErrTransactionClosed) {
+			err = errors.Join(err, abortErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// transact executa o trabalho, envia o offset do registro e confirma a transação
+func (c *kafkaConsumer[TData]) transact(ctx context.Context, tx *producerEngine.Transaction, record *kafka.Message, work func(tx *producerEngine.Transaction) error) error {
+	if work != nil {
+		if err := work(tx); err != nil {
+			return err
+		}
+	}
+
+	groupMetadata, err := c.client.GetConsumerGroupMetadata()
+	if err != nil {
+		return err
+	}
+
+	next := record.TopicPartition
+	next.Offset++
+	if err := tx.SendOffsets(ctx, []kafka.TopicPartition{next}, groupMetadata); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
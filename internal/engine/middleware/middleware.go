@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// Handler processa um registro Kafka. No consumo é o handler da aplicação para a mensagem consumida;
+// na publicação é o enfileiramento do registro no produtor.
+type Handler func(record *kafka.Message) error
+
+// Middleware envolve um Handler com um comportamento transversal (tempo, rastreamento, cabeçalhos,
+// autorização), podendo alterar o registro, interromper a cadeia retornando erro ou chamar next.
+type Middleware func(next Handler) Handler
+
+// PanicError é retornado pelo middleware Recover quando o handler entra em pânico
+type PanicError struct {
+	// Value é o valor informado ao panic
+	Value any
+	// Stack é a pilha da goroutine no momento do pânico
+	Stack []byte
+}
+
+// ErrTimeout indica que o handler não terminou dentro do tempo limite do middleware Timeout
+var ErrTimeout = errors.New("tempo limite do handler excedido")
+
+// outcome é o resultado de um handler executado pelo middleware Timeout
+type outcome struct {
+	err      error
+	panic    any
+	panicked bool
+}
+
+// ==========================================================================
+// Funções Públicas
+// ==========================================================================
+
+// Chain compõe os middlewares em volta do handler. Os middlewares executam na ordem informada:
+// o primeiro é o mais externo, recebe o registro antes dos demais e o resultado depois deles.
+func Chain(handler Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Recover converte um pânico dos middlewares seguintes ou do handler em um *PanicError,
+// tratado como qualquer erro do handler
+func Recover() Middleware {
+	return func(next Handler) Handler {
+		return func(record *kafka.Message) (err error) {
+			defer func() {
+				if value := recover(); value != nil {
+					err = &PanicError{Value: value, Stack: debug.Stack()}
+				}
+			}()
+			return next(record)
+		}
+	}
+}
+
+// Timeout retorna ErrTimeout quando os middlewares seguintes e o handler não terminam dentro do tempo
+// informado. O handler não é interrompido: ele continua em segundo plano e o seu resultado é descartado.
+// Um pânico do handler dentro do tempo é propagado para os middlewares anteriores.
+func Timeout(timeout time.Duration) Middleware {
+	return func(next Handler) Handler {
+		if timeout <= 0 {
+			return next
+		}
+
+		return func(record *kafka.Message) error {
+			done := make(chan outcome, 1)
+			go func() {
+				result := outcome{panicked: true}
+				defer func() {
+					if result.panicked {
+						result.panic = recover()
+					}
+					done <- result
+				}()
+				result.err = next(record)
+				result.panicked = false
+			}()
+
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+
+			select {
+			case result := <-done:
+				if result.panicked {
+					panic(result.panic)
+				}
+				return result.err
+			case <-timer.C:
+				return fmt.Errorf("%w: %s", ErrTimeout, timeout)
+			}
+		}
+	}
+}
+
+// ==========================================================================
+// Métodos PanicError
+// ==========================================================================
+
+// Error implementa a interface error
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("pânico no handler: %v", e.Value)
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Teste da ordem de execução da cadeia
+// O primeiro middleware é o mais externo: recebe o registro antes dos demais e o resultado depois deles.
+func TestChainOrder(t *testing.T) {
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(record *kafka.Message) error {
+				calls = append(calls, name+":antes")
+				err := next(record)
+				calls = append(calls, name+":depois")
+				return err
+			}
+		}
+	}
+
+	handler := Chain(func(record *kafka.Message) error {
+		calls = append(calls, "handler")
+		return nil
+	}, trace("a"), trace("b"))
+
+	require.NoError(t, handler(&kafka.Message{}))
+	assert.Equal(t, []string{"a:antes", "b:antes", "handler", "b:depois", "a:depois"}, calls)
+}
+
+// Teste da recuperação de pânicos
+// O pânico do handler, inclusive dentro do Timeout, deve ser convertido em *PanicError.
+func TestRecoverConvertsPanic(t *testing.T) {
+	handler := Chain(func(record *kafka.Message) error {
+		panic("falha inesperada")
+	}, Recover(), Timeout(time.Second))
+
+	err := handler(&kafka.Message{})
+
+	var panicErr *PanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, "falha inesperada", panicErr.Value)
+	assert.NotEmpty(t, panicErr.Stack)
+}
+
+// Teste do tempo limite
+// O handler que excede o tempo limite retorna ErrTimeout; dentro do tempo o erro do handler é preservado.
+func TestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	slow := Chain(func(record *kafka.Message) error {
+		<-release
+		return nil
+	}, Timeout(10*time.Millisecond))
+	assert.ErrorIs(t, slow(&kafka.Message{}), ErrTimeout)
+
+	failure := errors.New("falha do handler")
+	fast := Chain(func(record *kafka.Message) error {
+		return failure
+	}, Timeout(time.Second))
+	assert.ErrorIs(t, fast(&kafka.Message{}), failure)
+}
@@ -0,0 +1,173 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades Estáticas
+// ==========================================================================
+
+// queueFullBackoff é o intervalo de espera quando a fila local do produtor está cheia
+const queueFullBackoff = 10 * time.Millisecond
+
+// BatchPublishError indica que parte das mensagens de um lote não foi entregue.
+// O resultado individual de cada mensagem está no slice retornado por PublishBatch.
+type BatchPublishError struct {
+	// Failed é a quantidade de mensagens que falharam
+	Failed int
+	// Total é a quantidade de mensagens do lote
+	Total int
+}
+
+// ==========================================================================
+// Métodos Públicos
+// ==========================================================================
+
+// PublishBatch publica um lote de mensagens e aguarda o relatório de entrega de todas elas.
+// As mensagens são serializadas em paralelo e enfileiradas na ordem do slice, preservando a
+// ordem por partição. O resultado de índice i corresponde à mensagem de índice i.
+//
+// Parâmetros:
+//   - ctx: Contexto que limita o enfileiramento e a espera pelas confirmações
+//   - topic: Nome do tópico Kafka para publicação
+//   - messages: Mensagens tipadas a serem publicadas
+//   - serialization: Formato de serialização a ser usado
+//   - options: Middlewares que envolvem o enfileiramento de cada mensagem
+//
+// Retorno:
+//   - []DeliveryResult: Resultado de entrega de cada mensagem
+//   - error: *BatchPublishError se alguma mensagem falhar
+func (producer *kafkaProducer[TData]) PublishBatch(ctx context.Context, topic string, messages []message.Message[TData], serialization enums.Serialization, options PublishOptions) ([]DeliveryResult, error) {
+	results := make([]DeliveryResult, len(messages))
+	if len(messages) == 0 {
+		return results, nil
+	}
+
+	records := producer.buildBatch(topic, messages, serialization, results)
+
+	delivered := make(chan indexedResult, len(messages))
+	pending := 0
+	for index, record := range records {
+		if record == nil {
+			continue
+		}
+		record.Opaque = batchSlot{index: index, results: delivered}
+		err := intercept(record, options, func(record *kafka.Message) error {
+			return producer.produceWithBackoff(ctx, record)
+		})
+		if err != nil {
+			results[index] = notDelivered(topic, err)
+			continue
+		}
+		pending++
+	}
+
+	for ; pending > 0; pending-- {
+		select {
+		case delivery := <-delivered:
+			results[delivery.index] = delivery.result
+			records[delivery.index] = nil
+		case <-ctx.Done():
+			// Mensagens ainda sem relatório são marcadas com o erro do contexto
+			for index, record := range records {
+				if record != nil && results[index].Err == nil {
+					results[index] = notDelivered(topic, ctx.Err())
+				}
+			}
+			return results, batchError(results)
+		}
+	}
+
+	return results, batchError(results)
+}
+
+// Error implementa a interface error
+func (e *BatchPublishError) Error() string {
+	return fmt.Sprintf("%d de %d mensagens do lote não foram entregues", e.Failed, e.Total)
+}
+
+// ==========================================================================
+// Métodos Privados
+// ==========================================================================
+
+// buildBatch serializa as mensagens do lote em paralelo.
+// Mensagens que falham na serialização ficam nil no retorno e têm o erro registrado em results.
+func (producer *kafkaProducer[TData]) buildBatch(topic string, messages []message.Message[TData], serialization enums.Serialization, results []DeliveryResult) []*kafka.Message {
+	records := make([]*kafka.Message, len(messages))
+	workers := min(runtime.GOMAXPROCS(0), len(messages))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				record, err := producer.buildKafkaMessage(topic, messages[index], serialization)
+				if err != nil {
+					results[index] = notDelivered(topic, err)
+					continue
+				}
+				records[index] = record
+			}
+		}()
+	}
+
+	for index := range messages {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	return records
+}
+
+// produceWithBackoff enfileira a mensagem, aguardando enquanto a fila local do produtor estiver cheia
+func (producer *kafkaProducer[TData]) produceWithBackoff(ctx context.Context, record *kafka.Message) error {
+	for {
+		err := producer.client.Produce(record, nil)
+		var kafkaErr kafka.Error
+		if err == nil || !errors.As(err, &kafkaErr) || kafkaErr.Code() != kafka.ErrQueueFull {
+			return err
+		}
+
+		select {
+		case <-time.After(queueFullBackoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// ==========================================================================
+// Funções
+// ==========================================================================
+
+// notDelivered cria o resultado de uma mensagem que não chegou a ser entregue ao broker
+func notDelivered(topic string, err error) DeliveryResult {
+	return DeliveryResult{Topic: topic, Partition: kafka.PartitionAny, Offset: int64(kafka.OffsetInvalid), Err: err}
+}
+
+// batchError retorna *BatchPublishError quando algum resultado do lote contém erro
+func batchError(results []DeliveryResult) error {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return &BatchPublishError{Failed: failed, Total: len(results)}
+}
@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// Teste da serialização paralela do lote
+// Cada mensagem mantém sua posição e falhas de serialização ficam registradas no resultado.
+func TestBuildBatchKeepsOrderAndReportsFailures(t *testing.T) {
+	producer := &kafkaProducer[string]{}
+	producer.initializeSerializers()
+
+	messages := make([]message.Message[string], 50)
+	for index := range messages {
+		messages[index] = message.Message[string]{CorrelationId: uuid.New(), Data: "payload"}.WithStringKey(string(rune('a' + index%26)))
+	}
+
+	results := make([]DeliveryResult, len(messages))
+	records := producer.buildBatch("orders", messages, enums.JsonSerialization, results)
+	for index, record := range records {
+		assert.Equal(t, messages[index].Key, record.Key)
+	}
+	assert.NoError(t, batchError(results))
+
+	results = make([]DeliveryResult, len(messages))
+	records = producer.buildBatch("orders", messages, enums.Serialization(99), results)
+	assert.Nil(t, records[0])
+	assert.Error(t, results[0].Err)
+	assert.Equal(t, &BatchPublishError{Failed: 50, Total: 50}, batchError(results))
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/constants"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/ioc"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades Estáticas
+// ==========================================================================
+
+// closeFlushInterval é o intervalo de espera de cada Flush durante o fechamento, em milissegundos
+const closeFlushInterval = 100
+
+// ==========================================================================
+// Funções
+// ==========================================================================
+
+// Close aguarda a entrega das mensagens pendentes e fecha os produtores do container IoC,
+// inclusive o transacional, se configurado. Se o contexto terminar antes da entrega de todas
+// as mensagens, os produtores são fechados mesmo assim e o erro informa quantas ficaram pendentes.
+//
+// Parâmetros:
+//   - ctx: Contexto contendo o container IoC; limita a espera pelas entregas
+//
+// Retorno:
+//   - error: Erro caso mensagens fiquem sem entrega
+func Close(ctx context.Context) error {
+	container, ok := ctx.Value(constants.IocKey).(ioc.IContainer)
+	if !ok || container == nil {
+		return errors.New("IoC do Kafka não encontrado no contexto")
+	}
+
+	var errs []error
+	if producerSetup, _ := container.GetProducer(); producerSetup != nil {
+		errs = append(errs, closeClient(ctx, producerSetup.GetKafkaProducer()))
+	}
+	if transactionalSetup, _ := container.GetTransactionalProducer(); transactionalSetup != nil {
+		errs = append(errs, closeClient(ctx, transactionalSetup.GetKafkaProducer()))
+	}
+	return errors.Join(errs...)
+}
+
+// closeClient descarrega a fila do produtor até o fim das entregas ou do contexto e fecha o cliente
+func closeClient(ctx context.Context, client *kafka.Producer) error {
+	if client.IsClosed() {
+		return nil
+	}
+
+	var err error
+	for remaining := client.Flush(closeFlushInterval); remaining > 0; remaining = client.Flush(closeFlushInterval) {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("%d mensagem(ns) não entregue(s) antes do fechamento: %w", remaining, ctx.Err())
+			break
+		}
+	}
+
+	client.Close()
+	return err
+}
@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades Estáticas
+// ==========================================================================
+
+// DeliveryResult é o relatório de entrega de uma mensagem publicada.
+// Err é nil quando o broker confirmou a gravação da mensagem.
+type DeliveryResult struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Timestamp time.Time
+	Err       error
+}
+
+// deliveryReceiver recebe o relatório de entrega de uma mensagem; é transportado em kafka.Message.Opaque
+type deliveryReceiver interface {
+	deliver(result DeliveryResult)
+}
+
+// deliveryChan entrega o relatório de uma única mensagem publicada com PublishAsync
+type deliveryChan chan DeliveryResult
+
+// batchSlot entrega o relatório de uma mensagem de lote, identificada pela sua posição no lote
+type batchSlot struct {
+	index   int
+	results chan<- indexedResult
+}
+
+// indexedResult associa um relatório de entrega à posição da mensagem no lote
+type indexedResult struct {
+	index  int
+	result DeliveryResult
+}
+
+// deliveryLoops registra os produtores Kafka cujo loop de eventos já foi iniciado
+var deliveryLoops sync.Map
+
+// ==========================================================================
+// Funções
+// ==========================================================================
+
+// startDeliveryLoop inicia, uma única vez por produtor Kafka, a goroutine que consome producer.Events().
+// Os relatórios de entrega são encaminhados ao canal transportado em Opaque; relatórios sem canal
+// e erros do cliente são apenas registrados. O loop termina quando o produtor é fechado.
+func startDeliveryLoop(client *kafka.Producer, log logger.ILogger) {
+	if _, loaded := deliveryLoops.LoadOrStore(client, struct{}{}); loaded {
+		return
+	}
+
+	go func() {
+		for event := range client.Events() {
+			switch ev := event.(type) {
+			case *kafka.Message:
+				result := newDeliveryResult(ev)
+				if receiver, ok := ev.Opaque.(deliveryReceiver); ok {
+					receiver.deliver(result)
+					continue
+				}
+				if result.Err != nil {
+					log.Error("Delivery failed", messageFields(ev, logger.FieldError, result.Err)...)
+				}
+			case kafka.Error:
+				log.Error("Producer error", "code", ev.Code().String(), logger.FieldError, ev)
+			default:
+				log.Debug("Ignored event", "event", ev.String())
+			}
+		}
+		deliveryLoops.Delete(client)
+	}()
+}
+
+// newDeliveryResult converte o evento de entrega do librdkafka em DeliveryResult
+func newDeliveryResult(record *kafka.Message) DeliveryResult {
+	result := DeliveryResult{
+		Partition: record.TopicPartition.Partition,
+		Offset:    int64(record.TopicPartition.Offset),
+		Timestamp: record.Timestamp,
+		Err:       record.TopicPartition.Error,
+	}
+	if record.TopicPartition.Topic != nil {
+		result.Topic = *record.TopicPartition.Topic
+	}
+	return result
+}
+
+// messageFields retorna os campos estruturados de log de uma mensagem produzida:
+// tópico, partição, offset e correlationId. Os args são anexados ao final.
+func messageFields(record *kafka.Message, args ...any) []any {
+	topic := ""
+	if record.TopicPartition.Topic != nil {
+		topic = *record.TopicPartition.Topic
+	}
+	fields := []any{
+		logger.FieldTopic, topic,
+		logger.FieldPartition, record.TopicPartition.Partition,
+		logger.FieldOffset, int64(record.TopicPartition.Offset),
+	}
+	for _, header := range record.Headers {
+		if strings.EqualFold(header.Key, correlationIdHeader) {
+			fields = append(fields, logger.FieldCorrelationId, string(header.Value))
+			break
+		}
+	}
+	return append(fields, args...)
+}
+
+// ==========================================================================
+// Métodos
+// ==========================================================================
+
+// deliver envia o relatório ao canal, que possui buffer para uma mensagem
+func (c deliveryChan) deliver(result DeliveryResult) {
+	c <- result
+}
+
+// deliver envia o relatório ao canal do lote, que possui buffer para todas as mensagens
+func (s batchSlot) deliver(result DeliveryResult) {
+	s.results <- indexedResult{index: s.index, result: result}
+}
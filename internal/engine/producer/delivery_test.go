@@ -0,0 +1,23 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+// Teste da conversão do evento de entrega em DeliveryResult
+func TestNewDeliveryResult(t *testing.T) {
+	topic := "orders"
+	cause := errors.New("message timed out")
+	record := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 3, Offset: 42, Error: cause}}
+
+	result := newDeliveryResult(record)
+
+	assert.Equal(t, "orders", result.Topic)
+	assert.Equal(t, int32(3), result.Partition)
+	assert.Equal(t, int64(42), result.Offset)
+	assert.ErrorIs(t, result.Err, cause)
+}
@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/google/uuid"
+)
+
+// ==========================================================================
+// Tipos e Propriedades Estáticas
+// ==========================================================================
+
+const (
+	// legacyKeyMetadata é a entrada de Metadata usada como chave antes da existência de Message.Key
+	legacyKeyMetadata = "key"
+	// correlationIdHeader é o cabeçalho que transporta o CorrelationId da mensagem
+	correlationIdHeader = "correlationId"
+)
+
+// reservedHeaderPrefixes são os prefixos dos cabeçalhos de controle gerados pela biblioteca
+// (retentativas e dead-letter), que não devem ser repassados quando uma mensagem consumida é republicada
+var reservedHeaderPrefixes = []string{"x-retry-", "x-dlq-"}
+
+// ==========================================================================
+// Funções
+// ==========================================================================
+
+// buildHeaders converte os metadados da mensagem em cabeçalhos Kafka.
+// Entradas internas da biblioteca são descartadas e o cabeçalho de correlação
+// é sempre derivado de message.CorrelationId, omitido quando este é o UUID nulo.
+func buildHeaders[TData any](message message.Message[TData]) []kafka.Header {
+	headers := make([]kafka.Header, 0, len(message.Metadata)+1)
+	for key, value := range message.Metadata {
+		if isReservedHeader(key) {
+			continue
+		}
+		headers = append(headers, kafka.Header{Key: key, Value: value})
+	}
+	if message.CorrelationId != uuid.Nil {
+		headers = append(headers, kafka.Header{Key: correlationIdHeader, Value: []byte(message.CorrelationId.String())})
+	}
+	return headers
+}
+
+// isReservedHeader indica se a entrada de metadados é de uso interno da biblioteca
+func isReservedHeader(key string) bool {
+	if key == legacyKeyMetadata || strings.EqualFold(key, correlationIdHeader) {
+		return true
+	}
+	lower := strings.ToLower(key)
+	for _, prefix := range reservedHeaderPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
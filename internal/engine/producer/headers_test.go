@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// Teste da conversão de metadados em cabeçalhos
+// Entradas internas da biblioteca não são repassadas e o correlationId vem do campo CorrelationId.
+func TestBuildHeadersDropsInternalMetadata(t *testing.T) {
+	correlationId := uuid.New()
+	msg := message.Message[string]{
+		CorrelationId: correlationId,
+		Metadata: map[string][]byte{
+			"key":                  []byte("legacy"),
+			"correlationid":        []byte("stale"),
+			"x-retry-attempt":      []byte("2"),
+			"x-dlq-original-topic": []byte("orders"),
+			"source":               []byte("api"),
+		},
+	}
+
+	headers := buildHeaders(msg)
+
+	values := make(map[string]string, len(headers))
+	for _, header := range headers {
+		values[header.Key] = string(header.Value)
+	}
+	assert.Equal(t, map[string]string{
+		"source":        "api",
+		"correlationId": correlationId.String(),
+	}, values)
+}
+
+// Teste da mensagem sem CorrelationId
+// O UUID nulo não gera o cabeçalho de correlação nem é usado como chave de particionamento.
+func TestNilCorrelationIdIsNotPublished(t *testing.T) {
+	msg := message.Message[string]{Metadata: map[string][]byte{"source": []byte("api")}}
+
+	headers := buildHeaders(msg)
+	assert.Len(t, headers, 1)
+	assert.Equal(t, "source", headers[0].Key)
+
+	producer := &kafkaProducer[string]{}
+	assert.Nil(t, producer.messageKey(msg), "A chave nula deveria deixar o particionador distribuir as mensagens")
+
+	msg.CorrelationId = uuid.New()
+	assert.Equal(t, []byte(msg.CorrelationId.String()), producer.messageKey(msg))
+}
+
+// Teste da chave de NewForDataWithKey, que deve chegar ao campo Key e não aos metadados
+func TestNewForDataWithKeySetsKey(t *testing.T) {
+	msg, err := message.NewForDataWithKey(uuid.New(), "payload", "cliente-42", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("cliente-42"), msg.Key)
+	assert.NotContains(t, msg.Metadata, "cliente-42")
+}
@@ -1,6 +1,8 @@
 package engine
 
 import (
+	"context"
+
 	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
 	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
 )
@@ -12,5 +14,14 @@ import (
 // IKafkaProducer define a interface pública para produção de mensagens Kafka
 type IKafkaProducer[TData any] interface {
 	// Publish publica uma mensagem no tópico Kafka especificado
-	Publish(topic string, message message.Message[TData], serialization enums.Serialization) error
+	Publish(topic string, message message.Message[TData], serialization enums.Serialization, options PublishOptions) error
+
+	// PublishAsync publica uma mensagem e retorna um canal com o relatório de entrega
+	PublishAsync(topic string, message message.Message[TData], serialization enums.Serialization, options PublishOptions) (<-chan DeliveryResult, error)
+
+	// PublishAndWait publica uma mensagem e aguarda a confirmação do broker
+	PublishAndWait(ctx context.Context, topic string, message message.Message[TData], serialization enums.Serialization, options PublishOptions) (DeliveryResult, error)
+
+	// PublishBatch publica um lote de mensagens e aguarda a entrega de todas, retornando o resultado de cada uma
+	PublishBatch(ctx context.Context, topic string, messages []message.Message[TData], serialization enums.Serialization, options PublishOptions) ([]DeliveryResult, error)
 }
@@ -11,8 +11,10 @@ import (
 	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/constants"
 	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
 	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/ioc"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
 	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/google/uuid"
 )
 
 // ==========================================================================
@@ -26,6 +28,7 @@ type kafkaProducer[TData any] struct {
 	registry        setup.ISchemaRegistrySetup
 	serializers     map[enums.Serialization]func(topic string, payload *TData) ([]byte, error)
 	protobufAdapter *adapter.ProtobufAdapter // Adaptador para mensagens protobuf
+	logger          logger.ILogger
 }
 
 // ==========================================================================
@@ -51,9 +54,13 @@ func NewKafkaProducer[TData any](ctx context.Context) (IKafkaProducer[TData], er
 	producer.client = producerSetup.GetKafkaProducer()
 	producer.registry = schemaRegistry
 	producer.protobufAdapter = adapter.NewProtobufAdapter() // Inicializa o adaptador protobuf
+	producer.logger = container.GetLogger()
 
 	// Inicializa os serializadores suportados
 	producer.initializeSerializers()
+
+	// Garante o consumo dos relatórios de entrega do produtor compartilhado
+	startDeliveryLoop(producer.client, producer.logger)
 	return producer, nil
 }
 
@@ -61,65 +68,77 @@ func NewKafkaProducer[TData any](ctx context.Context) (IKafkaProducer[TData], er
 // Métodos Públicos
 // ==========================================================================
 
-// Publish publica uma mensagem fortemente tipada em um tópico Kafka.
-// Serializa a mensagem usando o formato especificado e gerencia metadados e chaves.
+// Publish enfileira uma mensagem fortemente tipada para publicação em um tópico Kafka.
+// Retorna assim que a mensagem é aceita pelo produtor, sem aguardar a confirmação do broker;
+// falhas de entrega são registradas pelo loop de eventos. Use PublishAndWait ou PublishAsync
+// para observar o resultado da entrega.
 //
 // Parâmetros:
 //   - topic: Nome do tópico Kafka para publicação
 //   - message: Mensagem tipada a ser publicada
 //   - serialization: Formato de serialização a ser usado
+//   - options: Middlewares que envolvem o enfileiramento
 //
 // Retorno:
-//   - error: Erro caso a publicação falhe
-func (producer *kafkaProducer[TData]) Publish(topic string, message message.Message[TData], serialization enums.Serialization) error {
-	var messageKey string
-
-	if keyBytes, exists := message.Metadata["key"]; exists {
-		messageKey = string(keyBytes)
-	} else {
-		messageKey = message.CorrelationId.String()
-	}
-
-	key, err := producer.serializeKey(messageKey)
+//   - error: Erro caso a serialização ou o enfileiramento falhem
+func (producer *kafkaProducer[TData]) Publish(topic string, message message.Message[TData], serialization enums.Serialization, options PublishOptions) error {
+	kafkaMessage, err := producer.buildKafkaMessage(topic, message, serialization)
 	if err != nil {
-		fmt.Println("Failed attempt to serialize key")
 		return err
 	}
+	return producer.produce(kafkaMessage, options)
+}
 
-	// Serializar apenas o campo Data, não a estrutura Message inteira
-	payload, err := producer.serializePayload(topic, message.Data, serialization)
+// PublishAsync enfileira uma mensagem e retorna um canal que recebe o relatório de entrega.
+// O canal recebe exatamente um DeliveryResult, com partição e offset ou o erro de entrega.
+//
+// Parâmetros:
+//   - topic: Nome do tópico Kafka para publicação
+//   - message: Mensagem tipada a ser publicada
+//   - serialization: Formato de serialização a ser usado
+//   - options: Middlewares que envolvem o enfileiramento
+//
+// Retorno:
+//   - <-chan DeliveryResult: Canal com o relatório de entrega
+//   - error: Erro caso a serialização ou o enfileiramento falhem
+func (producer *kafkaProducer[TData]) PublishAsync(topic string, message message.Message[TData], serialization enums.Serialization, options PublishOptions) (<-chan DeliveryResult, error) {
+	kafkaMessage, err := producer.buildKafkaMessage(topic, message, serialization)
 	if err != nil {
-		fmt.Println("Failed attempt to serialize value")
-		return err
-	}
-
-	// Convert metadata to Kafka headers
-	var headers []kafka.Header
-	// Add all metadata items as headers
-	for key, value := range message.Metadata {
-		if key != "key" {
-			headers = append(headers, kafka.Header{
-				Key:   key,
-				Value: value,
-			})
-		}
+		return nil, err
 	}
 
-	kafkaMessage := &kafka.Message{
-		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
-		Value:          payload,
-		Headers:        headers,
-		Key:            key,
+	delivery := make(deliveryChan, 1)
+	kafkaMessage.Opaque = delivery
+	if err := producer.produce(kafkaMessage, options); err != nil {
+		return nil, err
 	}
+	return delivery, nil
+}
 
-	err = producer.client.Produce(kafkaMessage, nil)
+// PublishAndWait publica uma mensagem e aguarda a confirmação do broker.
+//
+// Parâmetros:
+//   - ctx: Contexto que limita a espera pela confirmação
+//   - topic: Nome do tópico Kafka para publicação
+//   - message: Mensagem tipada a ser publicada
+//   - serialization: Formato de serialização a ser usado
+//   - options: Middlewares que envolvem o enfileiramento
+//
+// Retorno:
+//   - DeliveryResult: Relatório de entrega da mensagem
+//   - error: Erro de enfileiramento, de entrega ou de cancelamento do contexto
+func (producer *kafkaProducer[TData]) PublishAndWait(ctx context.Context, topic string, message message.Message[TData], serialization enums.Serialization, options PublishOptions) (DeliveryResult, error) {
+	delivery, err := producer.PublishAsync(topic, message, serialization, options)
 	if err != nil {
-		fmt.Printf("Failed when produce message: %v\n", err)
-		return err
+		return DeliveryResult{}, err
 	}
 
-	producer.client.Flush(10)
-	return nil
+	select {
+	case result := <-delivery:
+		return result, result.Err
+	case <-ctx.Done():
+		return DeliveryResult{}, ctx.Err()
+	}
 }
 
 // ProduceMessage publica uma mensagem Kafka pré-montada.
@@ -127,22 +146,64 @@ func (producer *kafkaProducer[TData]) Publish(topic string, message message.Mess
 //
 // Parâmetros:
 //   - msg: Mensagem Kafka pré-configurada
+//   - options: Middlewares que envolvem o enfileiramento
 //
 // Retorno:
 //   - error: Erro caso a publicação falhe
-func (producer *kafkaProducer[TData]) ProduceMessage(msg *kafka.Message) error {
-	err := producer.client.Produce(msg, nil)
-	if err != nil {
-		return err
-	}
-	producer.client.Flush(10)
-	return nil
+func (producer *kafkaProducer[TData]) ProduceMessage(msg *kafka.Message, options PublishOptions) error {
+	return producer.produce(msg, options)
 }
 
 // ==========================================================================
 // Métodos Privados
 // ==========================================================================
 
+// buildKafkaMessage monta a mensagem Kafka a partir da mensagem tipada.
+// Serializa chave e payload e aplica a partição de destino, o timestamp e os cabeçalhos.
+func (producer *kafkaProducer[TData]) buildKafkaMessage(topic string, message message.Message[TData], serialization enums.Serialization) (*kafka.Message, error) {
+	key, err := producer.serializeKey(producer.messageKey(message))
+	if err != nil {
+		return nil, fmt.Errorf("falha ao serializar chave: %w", err)
+	}
+
+	// Serializar apenas o campo Data, não a estrutura Message inteira
+	payload, err := producer.serializePayload(topic, message.Data, serialization)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao serializar valor: %w", err)
+	}
+
+	partition := kafka.PartitionAny
+	if message.TargetPartition != nil {
+		partition = *message.TargetPartition
+	}
+
+	kafkaMessage := &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: partition},
+		Value:          payload,
+		Headers:        buildHeaders(message),
+		Key:            key,
+	}
+
+	if !message.Timestamp.IsZero() {
+		kafkaMessage.Timestamp = message.Timestamp
+	}
+
+	return kafkaMessage, nil
+}
+
+// produce enfileira a mensagem no produtor, passando pelos middlewares da publicação;
+// o relatório de entrega chega pelo loop de eventos
+func (producer *kafkaProducer[TData]) produce(kafkaMessage *kafka.Message, options PublishOptions) error {
+	err := intercept(kafkaMessage, options, func(record *kafka.Message) error {
+		return producer.client.Produce(record, nil)
+	})
+	if err != nil {
+		producer.logger.Error("Failed when produce message", messageFields(kafkaMessage, logger.FieldError, err)...)
+		return err
+	}
+	return nil
+}
+
 // initializeSerializers configura todos os serializadores suportados pela biblioteca.
 // Centraliza a criação e registro dos serializadores padrão.
 func (producer *kafkaProducer[TData]) initializeSerializers() {
@@ -189,7 +250,23 @@ func (producer *kafkaProducer[TData]) serializePayload(topic string, payload TDa
 	return serializerFunc(topic, &payload)
 }
 
+// messageKey retorna a chave da mensagem.
+// Usa o campo Key; na ausência dele, a chave legada em Metadata["key"] e, por fim, o CorrelationId.
+// Sem nenhuma delas a chave é nula e o particionador distribui as mensagens entre as partições.
+func (producer *kafkaProducer[TData]) messageKey(message message.Message[TData]) []byte {
+	if len(message.Key) > 0 {
+		return message.Key
+	}
+	if keyBytes, exists := message.Metadata[legacyKeyMetadata]; exists {
+		return keyBytes
+	}
+	if message.CorrelationId == uuid.Nil {
+		return nil
+	}
+	return []byte(message.CorrelationId.String())
+}
+
 // serializeKey serializa a chave da mensagem para o formato usado pelo Kafka.
-func (producer *kafkaProducer[TData]) serializeKey(key string) ([]byte, error) {
-	return []byte(key), nil
+func (producer *kafkaProducer[TData]) serializeKey(key []byte) ([]byte, error) {
+	return key, nil
 }
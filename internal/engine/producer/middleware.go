@@ -0,0 +1,30 @@
+package engine
+
+import (
+	"github.com/Dieg657/kafka-toolkit-lib/internal/engine/middleware"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// PublishOptions reúne as configurações opcionais de uma publicação.
+// O valor zero mantém o comportamento padrão do produtor.
+type PublishOptions struct {
+	// Middlewares envolvem o enfileiramento do registro já serializado, na ordem informada
+	// (o primeiro é o mais externo)
+	Middlewares []middleware.Middleware
+}
+
+// ==========================================================================
+// Funções Privadas
+// ==========================================================================
+
+// intercept executa o enfileiramento do registro dentro da cadeia de middlewares da publicação
+func intercept(record *kafka.Message, options PublishOptions, enqueue middleware.Handler) error {
+	if len(options.Middlewares) == 0 {
+		return enqueue(record)
+	}
+	return middleware.Chain(enqueue, options.Middlewares...)(record)
+}
@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Dieg657/kafka-toolkit-lib/internal/engine/middleware"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+// Teste dos middlewares por publicação
+// Cada publicação executa apenas a cadeia das suas opções; publicações sem middlewares enfileiram diretamente.
+func TestInterceptAppliesOnlyPublishMiddlewares(t *testing.T) {
+	var calls []string
+	tagging := func(name string) middleware.Middleware {
+		return func(next middleware.Handler) middleware.Handler {
+			return func(record *kafka.Message) error {
+				calls = append(calls, name)
+				return next(record)
+			}
+		}
+	}
+	enqueued := 0
+	enqueue := func(*kafka.Message) error {
+		enqueued++
+		return nil
+	}
+
+	assert.NoError(t, intercept(&kafka.Message{}, PublishOptions{Middlewares: []middleware.Middleware{tagging("a"), tagging("b")}}, enqueue))
+	assert.NoError(t, intercept(&kafka.Message{}, PublishOptions{Middlewares: []middleware.Middleware{tagging("c")}}, enqueue))
+	assert.NoError(t, intercept(&kafka.Message{}, PublishOptions{}, enqueue))
+	assert.Equal(t, []string{"a", "b", "c"}, calls, "Cada publicação deveria executar apenas os seus middlewares")
+	assert.Equal(t, 3, enqueued)
+
+	rejected := errors.New("publicação não autorizada")
+	deny := func(next middleware.Handler) middleware.Handler {
+		return func(*kafka.Message) error { return rejected }
+	}
+	assert.ErrorIs(t, intercept(&kafka.Message{}, PublishOptions{Middlewares: []middleware.Middleware{deny}}, enqueue), rejected)
+	assert.Equal(t, 3, enqueued, "O middleware deveria poder recusar a publicação")
+}
@@ -0,0 +1,316 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/internal/common/setup"
+	"github.com/Dieg657/kafka-toolkit-lib/internal/engine/adapter"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/constants"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/ioc"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades Estáticas
+// ==========================================================================
+
+// ErrTransactionClosed indica uso de uma transação já confirmada ou abortada
+var ErrTransactionClosed = errors.New("transação já finalizada")
+
+// ErrTransactionalProducerFatal indica um erro fatal do produtor transacional, por exemplo quando outra
+// instância assume o mesmo transactional.id. Nenhuma transação pode mais ser executada nesse produtor:
+// ele deve ser recriado, o que exige um novo container IoC.
+var ErrTransactionalProducerFatal = errors.New("erro fatal no produtor transacional: o produtor deve ser recriado")
+
+// Transaction representa uma transação em andamento no produtor transacional.
+// Mensagens publicadas e offsets enviados na transação são confirmados ou descartados juntos.
+// O produtor transacional executa uma transação por vez: BeginTransaction aguarda a
+// finalização da transação anterior.
+type Transaction struct {
+	client   *kafka.Producer
+	registry setup.ISchemaRegistrySetup
+	logger   logger.ILogger
+	state    *transactionalState
+	mu       sync.Mutex
+	closed   bool
+}
+
+// transactionalState controla a inicialização e a exclusividade de um produtor transacional
+type transactionalState struct {
+	slot        chan struct{}
+	initMu      sync.Mutex
+	initialized bool // InitTransactions concluído com sucesso
+}
+
+// transactionalStates registra o estado de cada produtor transacional
+var transactionalStates sync.Map
+
+const (
+	// commitRetryBackoff é a espera inicial entre as tentativas de confirmar uma transação
+	commitRetryBackoff = 100 * time.Millisecond
+	// maxCommitRetryBackoff limita a espera entre as tentativas de confirmar uma transação
+	maxCommitRetryBackoff = 2 * time.Second
+	// abortTimeout limita o aborto de uma transação cuja confirmação falhou
+	abortTimeout = 30 * time.Second
+)
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// BeginTransaction inicia uma transação no produtor transacional do container IoC.
+// Na primeira chamada as transações do produtor são inicializadas (InitTransactions); se a
+// inicialização falhar, ela é repetida na chamada seguinte.
+//
+// Parâmetros:
+//   - ctx: Contexto contendo o container IoC; limita a espera pelo produtor e pela inicialização
+//
+// Retorno:
+//   - *Transaction: Transação iniciada
+//   - error: Erro caso o produtor transacional não esteja configurado ou a transação não possa ser iniciada
+func BeginTransaction(ctx context.Context) (*Transaction, error) {
+	container, ok := ctx.Value(constants.IocKey).(ioc.IContainer)
+	if !ok || container == nil {
+		return nil, errors.New("IoC do Kafka não encontrado no contexto")
+	}
+
+	producerSetup, registry := container.GetTransactionalProducer()
+	if producerSetup == nil {
+		return nil, errors.New("produtor transacional não configurado: informe KAFKA_TRANSACTIONAL_ID")
+	}
+
+	client := producerSetup.GetKafkaProducer()
+	startDeliveryLoop(client, container.GetLogger())
+
+	value, _ := transactionalStates.LoadOrStore(client, &transactionalState{slot: make(chan struct{}, 1)})
+	state := value.(*transactionalState)
+
+	if err := state.initialize(ctx, client.InitTransactions); err != nil {
+		return nil, fmt.Errorf("falha ao inicializar transações: %w", err)
+	}
+
+	select {
+	case state.slot <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if err := client.BeginTransaction(); err != nil {
+		<-state.slot
+		return nil, err
+	}
+
+	return &Transaction{client: client, registry: registry, logger: container.GetLogger(), state: state}, nil
+}
+
+// ==========================================================================
+// Métodos Públicos
+// ==========================================================================
+
+// SendOffsets inclui na transação os offsets consumidos pelo grupo informado.
+// Os offsets devem apontar para a próxima mensagem a ser lida (offset da mensagem + 1).
+//
+// Parâmetros:
+//   - ctx: Contexto que limita a operação
+//   - offsets: Offsets a confirmar junto com a transação
+//   - groupMetadata: Metadados do grupo de consumidores dono dos offsets
+//
+// Retorno:
+//   - error: Erro caso os offsets não possam ser enviados
+func (tx *Transaction) SendOffsets(ctx context.Context, offsets []kafka.TopicPartition, groupMetadata *kafka.ConsumerGroupMetadata) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.closed {
+		return ErrTransactionClosed
+	}
+	return tx.client.SendOffsetsToTransaction(ctx, offsets, groupMetadata)
+}
+
+// Commit confirma a transação, tornando visíveis as mensagens e os offsets enviados.
+// Erros recuperáveis são repetidos com espera crescente entre as tentativas. Nas demais falhas a
+// transação é abortada antes de ser finalizada, para que o produtor aceite a próxima transação;
+// erros fatais não podem ser abortados e são retornados com ErrTransactionalProducerFatal.
+//
+// Parâmetros:
+//   - ctx: Contexto que limita a confirmação
+//
+// Retorno:
+//   - error: Erro caso a transação não possa ser confirmada
+func (tx *Transaction) Commit(ctx context.Context) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.closed {
+		return ErrTransactionClosed
+	}
+
+	backoff := commitRetryBackoff
+	for {
+		err := tx.client.CommitTransaction(ctx)
+		if err == nil {
+			tx.close()
+			return nil
+		}
+
+		var kafkaErr kafka.Error
+		if errors.As(err, &kafkaErr) && kafkaErr.IsRetriable() && !kafkaErr.IsFatal() && ctx.Err() == nil {
+			tx.logger.Warn("Error on commit transaction, retrying", "backoff", backoff.String(), logger.FieldError, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(backoff):
+				backoff = min(backoff*2, maxCommitRetryBackoff)
+				continue
+			}
+		}
+
+		err = tx.abortFailed(err)
+		tx.close()
+		return fmt.Errorf("falha ao confirmar transação: %w", err)
+	}
+}
+
+// Abort descarta a transação: mensagens publicadas e offsets enviados não são confirmados.
+//
+// Parâmetros:
+//   - ctx: Contexto que limita a operação
+//
+// Retorno:
+//   - error: Erro caso a transação não possa ser abortada
+func (tx *Transaction) Abort(ctx context.Context) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.closed {
+		return ErrTransactionClosed
+	}
+
+	err := tx.client.AbortTransaction(ctx)
+	tx.close()
+	if isFatal(err) {
+		return fmt.Errorf("%w: %w", ErrTransactionalProducerFatal, err)
+	}
+	return err
+}
+
+// ==========================================================================
+// Funções
+// ==========================================================================
+
+// PublishInTransaction publica uma mensagem tipada dentro da transação.
+// Mensagens de tipos diferentes podem ser publicadas na mesma transação.
+// A entrega só se torna visível aos consumidores read_committed após o Commit.
+//
+// Parâmetros:
+//   - tx: Transação em andamento
+//   - topic: Nome do tópico Kafka para publicação
+//   - message: Mensagem tipada a ser publicada
+//   - serialization: Formato de serialização a ser usado
+//   - options: Middlewares que envolvem o enfileiramento
+//
+// Retorno:
+//   - error: Erro caso a serialização ou o enfileiramento falhem
+func PublishInTransaction[TData any](tx *Transaction, topic string, message message.Message[TData], serialization enums.Serialization, options PublishOptions) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.closed {
+		return ErrTransactionClosed
+	}
+
+	producer := &kafkaProducer[TData]{
+		client:          tx.client,
+		registry:        tx.registry,
+		protobufAdapter: adapter.NewProtobufAdapter(),
+		logger:          tx.logger,
+	}
+	producer.initializeSerializers()
+
+	return producer.Publish(topic, message, serialization, options)
+}
+
+// ProduceInTransaction publica um registro Kafka já montado dentro da transação, sem serialização nem
+// middlewares. Usado pelo consumo transacional para encaminhar à dead-letter na mesma transação do offset.
+//
+// Parâmetros:
+//   - tx: Transação em andamento
+//   - record: Registro a ser publicado, com tópico, chave, valor e cabeçalhos definidos
+//
+// Retorno:
+//   - error: Erro caso o enfileiramento falhe
+func ProduceInTransaction(tx *Transaction, record *kafka.Message) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.closed {
+		return ErrTransactionClosed
+	}
+
+	if err := tx.client.Produce(record, nil); err != nil {
+		tx.logger.Error("Failed when produce message", messageFields(record, logger.FieldError, err)...)
+		return err
+	}
+	return nil
+}
+
+// ==========================================================================
+// Métodos Privados
+// ==========================================================================
+
+// initialize executa a inicialização das transações do produtor uma única vez com sucesso.
+// Uma falha não é memorizada: a próxima transação tenta inicializar novamente.
+func (state *transactionalState) initialize(ctx context.Context, initTransactions func(ctx context.Context) error) error {
+	state.initMu.Lock()
+	defer state.initMu.Unlock()
+
+	if state.initialized {
+		return nil
+	}
+	if err := initTransactions(ctx); err != nil {
+		return err
+	}
+	state.initialized = true
+	return nil
+}
+
+// abortFailed aborta a transação cuja confirmação falhou, com um prazo próprio, pois o contexto da
+// confirmação pode já ter expirado. Retorna o erro da confirmação, acrescido do erro do aborto, se houver,
+// e marcado com ErrTransactionalProducerFatal quando o produtor não pode mais ser usado.
+func (tx *Transaction) abortFailed(cause error) error {
+	if isFatal(cause) {
+		tx.logger.Error("Fatal transactional producer error", logger.FieldError, cause)
+		return fmt.Errorf("%w: %w", ErrTransactionalProducerFatal, cause)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), abortTimeout)
+	defer cancel()
+
+	abortErr := tx.client.AbortTransaction(ctx)
+	if abortErr == nil {
+		return cause
+	}
+	tx.logger.Error("Error on abort transaction after failed commit", logger.FieldError, abortErr)
+	if isFatal(abortErr) {
+		return fmt.Errorf("%w: %w", ErrTransactionalProducerFatal, errors.Join(cause, abortErr))
+	}
+	return errors.Join(cause, abortErr)
+}
+
+// close marca a transação como finalizada e libera o produtor para a próxima transação
+func (tx *Transaction) close() {
+	tx.closed = true
+	<-tx.state.slot
+}
+
+// isFatal indica se o erro é fatal para o produtor transacional
+func isFatal(err error) bool {
+	var kafkaErr kafka.Error
+	return errors.As(err, &kafkaErr) && kafkaErr.IsFatal()
+}
@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+// Teste da inicialização do produtor transacional
+// Uma falha transitória não impede as transações seguintes, e após o sucesso a inicialização não se repete.
+func TestTransactionalStateRetriesFailedInitialization(t *testing.T) {
+	state := &transactionalState{slot: make(chan struct{}, 1)}
+	calls := 0
+	initTransactions := func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return errors.New("broker indisponível")
+		}
+		return nil
+	}
+
+	assert.Error(t, state.initialize(context.Background(), initTransactions))
+	assert.NoError(t, state.initialize(context.Background(), initTransactions), "A falha anterior não deveria ser memorizada")
+	assert.NoError(t, state.initialize(context.Background(), initTransactions))
+	assert.Equal(t, 2, calls)
+}
+
+// Teste da classificação de erros fatais do produtor transacional
+// Apenas erros Kafka fatais, inclusive encapsulados, impedem o aborto da transação.
+func TestIsFatal(t *testing.T) {
+	fenced := kafka.NewError(kafka.ErrFenced, "producer fenced", true)
+
+	assert.True(t, isFatal(fenced))
+	assert.True(t, isFatal(fmt.Errorf("falha ao confirmar transação: %w", fenced)))
+	assert.False(t, isFatal(kafka.NewError(kafka.ErrTimedOut, "timeout", false)))
+	assert.False(t, isFatal(errors.New("falha")))
+	assert.False(t, isFatal(nil))
+}
@@ -0,0 +1,82 @@
+package enums
+
+// ==========================================================================
+// Configurações por Assinatura
+// ==========================================================================
+
+// ConsumerPriority define a prioridade de uma assinatura, com os mesmos níveis
+// aceitos pela variável de ambiente KAFKA_CONSUMER_PRIORITY.
+type ConsumerPriority string
+
+const (
+	// ConsumerPriorityOrder prioriza a ordem e a consistência
+	ConsumerPriorityOrder ConsumerPriority = "ORDER"
+
+	// ConsumerPriorityBalanced equilibra consistência e desempenho
+	ConsumerPriorityBalanced ConsumerPriority = "BALANCED"
+
+	// ConsumerPriorityHighPerformance prioriza o throughput
+	ConsumerPriorityHighPerformance ConsumerPriority = "HIGH_PERFORMANCE"
+
+	// ConsumerPriorityRisky prioriza o desempenho máximo, com risco de perda de mensagens
+	ConsumerPriorityRisky ConsumerPriority = "RISKY"
+)
+
+// AutoOffsetReset define onde uma assinatura começa a consumir quando o grupo
+// ainda não possui offset confirmado para a partição.
+type AutoOffsetReset string
+
+const (
+	// OffsetResetEarliest inicia pela mensagem mais antiga disponível
+	OffsetResetEarliest AutoOffsetReset = "earliest"
+
+	// OffsetResetLatest inicia apenas com as mensagens que chegarem após a conexão
+	OffsetResetLatest AutoOffsetReset = "latest"
+
+	// OffsetResetError encerra o consumo com erro quando não há offset válido
+	OffsetResetError AutoOffsetReset = "error"
+)
+
+// AssignmentStrategy define como as partições são distribuídas entre os membros do grupo
+// (partition.assignment.strategy do librdkafka).
+type AssignmentStrategy string
+
+const (
+	// AssignmentRange atribui faixas contíguas de partições de cada tópico (protocolo eager)
+	AssignmentRange AssignmentStrategy = "range"
+
+	// AssignmentRoundRobin distribui as partições alternadamente entre os membros (protocolo eager)
+	AssignmentRoundRobin AssignmentStrategy = "roundrobin"
+
+	// AssignmentCooperativeSticky usa o protocolo cooperativo: apenas as partições que mudam de dono
+	// são revogadas, e os demais membros continuam consumindo durante o rebalanceamento
+	AssignmentCooperativeSticky AssignmentStrategy = "cooperative-sticky"
+)
+
+// CommitMode define como uma assinatura confirma os offsets das mensagens processadas.
+// Em todos os modos apenas offsets de mensagens já tratadas são confirmados, e um commit
+// síncrono final é feito na revogação das partições e no encerramento.
+type CommitMode string
+
+const (
+	// CommitPerMessage confirma de forma síncrona o offset de cada mensagem tratada
+	CommitPerMessage CommitMode = "PER_MESSAGE"
+
+	// CommitPeriodic armazena os offsets tratados e os confirma de forma assíncrona a cada intervalo (padrão)
+	CommitPeriodic CommitMode = "PERIODIC"
+
+	// CommitManual confirma apenas os offsets informados pela aplicação com Controller.Commit
+	CommitManual CommitMode = "MANUAL"
+)
+
+// WatchdogAction define o que o watchdog faz quando um handler com contexto excede o prazo da mensagem
+type WatchdogAction string
+
+const (
+	// WatchdogReport registra o handler atrasado em log e aguarda o seu término (padrão)
+	WatchdogReport WatchdogAction = "REPORT"
+
+	// WatchdogAbort registra o handler atrasado e o abandona, tratando a mensagem como falha,
+	// para que o laço de consumo volte a chamar poll antes de exceder max.poll.interval.ms
+	WatchdogAbort WatchdogAction = "ABORT"
+)
@@ -0,0 +1,35 @@
+package enums
+
+// ==========================================================================
+// Ordenação no Consumo Paralelo
+// ==========================================================================
+
+// ParallelOrdering define a chave usada para distribuir mensagens entre os workers
+// no consumo paralelo.
+//
+// Mensagens que compartilham a mesma chave de ordenação são sempre processadas pelo
+// mesmo worker, na ordem em que foram lidas do Kafka.
+type ParallelOrdering int
+
+const (
+	// OrderingByKey preserva a ordem entre mensagens com a mesma chave Kafka.
+	//
+	// Características:
+	// - Maior paralelismo: mensagens de uma mesma partição com chaves diferentes
+	//   podem ser processadas simultaneamente
+	// - Mensagens sem chave são ordenadas pela partição de origem
+	//
+	// Uso recomendado:
+	// - Eventos de entidades (pedidos, contas, usuários) onde a chave identifica a entidade
+	OrderingByKey ParallelOrdering = iota
+
+	// OrderingByPartition preserva a ordem de todas as mensagens de uma mesma partição.
+	//
+	// Características:
+	// - Mesmo comportamento de ordenação do consumo sequencial
+	// - Paralelismo limitado ao número de partições atribuídas
+	//
+	// Uso recomendado:
+	// - Tópicos cuja ordem relevante é a da partição e não a da chave
+	OrderingByPartition ParallelOrdering = 1
+)
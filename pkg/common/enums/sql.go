@@ -0,0 +1,17 @@
+package enums
+
+// ==========================================================================
+// Marcadores de Parâmetros SQL
+// ==========================================================================
+
+// SQLPlaceholder define o marcador de parâmetros usado pelo driver database/sql nas consultas
+// dos componentes persistidos em banco (deduplicação e outbox).
+type SQLPlaceholder int
+
+const (
+	// PlaceholderQuestion usa "?" (SQLite, MySQL, SQL Server com go-mssqldb) (padrão)
+	PlaceholderQuestion SQLPlaceholder = iota
+
+	// PlaceholderDollar usa "$1", "$2"... (PostgreSQL com pgx ou lib/pq)
+	PlaceholderDollar SQLPlaceholder = 1
+)
@@ -16,7 +16,7 @@ const (
 	// uma mensagem não pode ser deserializada.
 	//
 	// Características:
-	// - Para completamente o consumer ao encontrar erro de deserialização
+	// - Encerra o consumo e retorna um *DeserializationError sem confirmar a mensagem
 	// - Mais restritivo e seguro para ambientes críticos
 	// - Garante que nenhuma mensagem malformada seja ignorada
 	// - Requer intervenção manual para resolver o problema
@@ -42,4 +42,29 @@ const (
 	// - Cenários de desenvolvimento e teste
 	// - Processamento em batch onde algumas falhas são aceitáveis
 	OnDeserializationIgnoreMessage DeserializationStrategy = 1
+
+	// OnDeserializationFailedSendToDeadLetter encaminha a mensagem que falhou na deserialização
+	// para o tópico de dead-letter e continua processando as próximas mensagens.
+	//
+	// Características:
+	// - A mensagem é publicada com os bytes e cabeçalhos originais, mais os cabeçalhos de diagnóstico
+	// - Usa o tópico configurado com WithDeadLetterTopic ou, se ausente, "<tópico>.dlq"
+	// - Se o encaminhamento falhar, o consumo é encerrado sem confirmar a mensagem
+	//
+	// Uso recomendado:
+	// - Sistemas que não podem perder mensagens, mas também não podem parar por uma mensagem malformada
+	// - Cenários onde as mensagens rejeitadas serão analisadas ou reprocessadas posteriormente
+	OnDeserializationFailedSendToDeadLetter DeserializationStrategy = 2
+
+	// OnDeserializationFailedInvokeCallback invoca o callback configurado com WithDeserializationFailureCallback,
+	// entregando a mensagem Kafka original e o erro de deserialização.
+	//
+	// Características:
+	// - O handler não é chamado para a mensagem
+	// - Se o callback retornar nil, a mensagem é confirmada e o consumo continua
+	// - Se o callback retornar erro, o consumo é encerrado com esse erro
+	//
+	// Uso recomendado:
+	// - Tratamentos específicos da aplicação (persistência, alertas, conversões de legado)
+	OnDeserializationFailedInvokeCallback DeserializationStrategy = 3
 )
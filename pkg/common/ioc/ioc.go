@@ -9,10 +9,12 @@ import (
 
 	"github.com/Dieg657/kafka-toolkit-lib/internal/common/config"
 	"github.com/Dieg657/kafka-toolkit-lib/internal/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/internal/common/logging"
 	"github.com/Dieg657/kafka-toolkit-lib/internal/common/setup"
 	consumerModule "github.com/Dieg657/kafka-toolkit-lib/internal/common/setup/consumer"
 	producerModule "github.com/Dieg657/kafka-toolkit-lib/internal/common/setup/producer"
 	registryModule "github.com/Dieg657/kafka-toolkit-lib/internal/common/setup/schema_registry"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
 )
 
 // ==========================================================================
@@ -27,6 +29,10 @@ type IContainer interface {
 	// GetProducer retorna interfaces para producer e schema registry
 	GetProducer() (setup.IKafkaProducerSetup, setup.ISchemaRegistrySetup)
 
+	// GetTransactionalProducer retorna o produtor transacional e o schema registry.
+	// O produtor é nil quando KAFKA_TRANSACTIONAL_ID não foi informado.
+	GetTransactionalProducer() (setup.IKafkaProducerSetup, setup.ISchemaRegistrySetup)
+
 	// GetSchemaRegistry retorna a interface para o schema registry
 	GetSchemaRegistry() setup.ISchemaRegistrySetup
 
@@ -35,6 +41,9 @@ type IContainer interface {
 
 	// GetProducerPriority retorna a prioridade do produtor
 	GetProducerPriority() enums.ProducerOrderPriority
+
+	// GetLogger retorna o logger do container, definido com WithLogger na sua criação
+	GetLogger() logger.ILogger
 }
 
 // ==========================================================================
@@ -51,9 +60,11 @@ var (
 type kafkaIoC struct {
 	consumerSetup       setup.IKafkaConsumerSetup
 	producerSetup       setup.IKafkaProducerSetup
+	transactionalSetup  setup.IKafkaProducerSetup
 	schemaRegistrySetup setup.ISchemaRegistrySetup
 	consumerPriority    enums.ConsumerOrderPriority
 	producerPriority    enums.ProducerOrderPriority
+	logger              logger.ILogger
 }
 
 // Mapas para normalização dos parâmetros
@@ -157,21 +168,30 @@ func mapConsumerPriorityToKafka(value string) string {
 // Factory
 // ==========================================================================
 
-// GetKafkaIoC retorna uma instância única do container de dependências
-func GetKafkaIoC() (IContainer, error) {
+// GetKafkaIoC retorna uma instância única do container de dependências.
+// As opções são aplicadas pela chamada que cria o container; nas chamadas seguintes são ignoradas.
+func GetKafkaIoC(options ...Option) (IContainer, error) {
+	created := false
 	once.Do(func() {
 		var err error
-		iocContainer, err = newKafkaIoC()
+		created = true
+		iocContainer, err = newKafkaIoC(options...)
 		if err != nil {
 			panic(err)
 		}
 	})
+	if !created && len(options) > 0 {
+		iocContainer.GetLogger().Warn("Container options ignored: container already created")
+	}
 	return iocContainer, nil
 }
 
-// newKafkaIoC cria e retorna uma nova instância de IContainer
-func newKafkaIoC() (IContainer, error) {
-	ioc := &kafkaIoC{}
+// newKafkaIoC cria e retorna uma nova instância de IContainer com as opções informadas
+func newKafkaIoC(options ...Option) (IContainer, error) {
+	ioc := &kafkaIoC{logger: logger.NewSlogLogger(nil)}
+	for _, option := range options {
+		option(ioc)
+	}
 	err := ioc.initialize()
 	if err != nil {
 		return nil, err
@@ -257,6 +277,12 @@ func (ioc *kafkaIoC) initialize() error {
 	// Configura a prioridade do consumidor
 	kafkaOptions.SetConsumerPriority(consumerPriority)
 
+	// Configura o transactional.id do produtor transacional (opcional)
+	kafkaOptions.SetTransactionalId(viper.GetString("KAFKA_TRANSACTIONAL_ID"))
+
+	// Configura a estratégia de atribuição de partições dos consumidores (opcional)
+	kafkaOptions.SetAssignmentStrategy(viper.GetString("KAFKA_PARTITION_ASSIGNMENT_STRATEGY"))
+
 	// Configura o schema registry
 	kafkaOptions.SetSchemaRegistry(schemaRegistryOptions)
 
@@ -278,6 +304,17 @@ func (ioc *kafkaIoC) initialize() error {
 	}
 
 	ioc.producerSetup = producerSetup
+	logging.ForwardClientLogs(ioc.logger, "producer", producerSetup.GetKafkaProducer().Logs())
+
+	// Cria o produtor transacional, se configurado
+	if kafkaOptions.GetTransactionalId() != "" {
+		transactionalSetup, err := producerModule.NewKafkaTransactionalProducerSetup(kafkaOptions)
+		if err != nil {
+			return fmt.Errorf("falha ao configurar Producer transacional: %w", err)
+		}
+		ioc.transactionalSetup = transactionalSetup
+		logging.ForwardClientLogs(ioc.logger, "transactional-producer", transactionalSetup.GetKafkaProducer().Logs())
+	}
 
 	// Cria o consumidor
 	consumerSetup, err := consumerModule.NewKafkaConsumerSetup(kafkaOptions)
@@ -307,6 +344,11 @@ func (ioc *kafkaIoC) GetProducer() (setup.IKafkaProducerSetup, setup.ISchemaRegi
 	return ioc.producerSetup, ioc.schemaRegistrySetup
 }
 
+// GetTransactionalProducer retorna o produtor transacional, se configurado, e o schema registry
+func (ioc *kafkaIoC) GetTransactionalProducer() (setup.IKafkaProducerSetup, setup.ISchemaRegistrySetup) {
+	return ioc.transactionalSetup, ioc.schemaRegistrySetup
+}
+
 // GetSchemaRegistry retorna a interface para o schema registry
 func (ioc *kafkaIoC) GetSchemaRegistry() setup.ISchemaRegistrySetup {
 	return ioc.schemaRegistrySetup
@@ -321,3 +363,8 @@ func (ioc *kafkaIoC) GetConsumerPriority() enums.ConsumerOrderPriority {
 func (ioc *kafkaIoC) GetProducerPriority() enums.ProducerOrderPriority {
 	return ioc.producerPriority
 }
+
+// GetLogger retorna o logger do container
+func (ioc *kafkaIoC) GetLogger() logger.ILogger {
+	return ioc.logger
+}
@@ -0,0 +1,27 @@
+package ioc
+
+import (
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// Option configura o container de dependências na sua criação
+type Option func(ioc *kafkaIoC)
+
+// ==========================================================================
+// Funções Públicas
+// ==========================================================================
+
+// WithLogger define o logger do container, usado pelos consumidores, produtores e relays criados a partir
+// dele, inclusive para os logs do librdkafka. Sem esta opção, ou com nil, os logs são escritos em slog.Default();
+// use logger.NewNopLogger() para silenciar a biblioteca.
+func WithLogger(l logger.ILogger) Option {
+	return func(ioc *kafkaIoC) {
+		if l != nil {
+			ioc.logger = l
+		}
+	}
+}
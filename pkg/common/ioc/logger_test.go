@@ -0,0 +1,26 @@
+package ioc
+
+import (
+	"testing"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Teste do logger definido na criação do container
+// Cada container guarda o seu próprio logger; sem WithLogger, ou com nil, é usado o logger padrão do slog.
+func TestContainerLogger(t *testing.T) {
+	setDummyKafkaEnv()
+
+	silent := logger.NewNopLogger()
+	container, err := newKafkaIoC(WithLogger(silent))
+	require.NoError(t, err)
+	assert.Equal(t, silent, container.GetLogger())
+
+	for _, options := range [][]Option{nil, {WithLogger(nil)}} {
+		container, err := newKafkaIoC(options...)
+		require.NoError(t, err)
+		assert.IsType(t, logger.NewSlogLogger(nil), container.GetLogger(), "Sem logger informado deveria ser usado o slog padrão")
+	}
+}
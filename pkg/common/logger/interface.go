@@ -0,0 +1,21 @@
+package logger
+
+// ==========================================================================
+// Interfaces
+// ==========================================================================
+
+// ILogger define a interface de log estruturado usada pela biblioteca.
+// Os argumentos seguem a convenção do log/slog: pares chave-valor após a mensagem.
+type ILogger interface {
+	// Debug registra eventos de diagnóstico
+	Debug(msg string, args ...any)
+
+	// Info registra eventos informativos do ciclo de vida
+	Info(msg string, args ...any)
+
+	// Warn registra situações inesperadas que não interrompem o processamento
+	Warn(msg string, args ...any)
+
+	// Error registra falhas de processamento
+	Error(msg string, args ...any)
+}
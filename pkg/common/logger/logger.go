@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// Chaves dos campos estruturados adicionados pela biblioteca
+const (
+	FieldTopic         = "topic"
+	FieldPartition     = "partition"
+	FieldOffset        = "offset"
+	FieldCorrelationId = "correlationId"
+	FieldError         = "error"
+)
+
+// slogLogger adapta um *slog.Logger à interface ILogger
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// nopLogger descarta todas as mensagens
+type nopLogger struct{}
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// NewSlogLogger cria um ILogger que escreve no *slog.Logger informado.
+// Com logger nil é usado slog.Default().
+func NewSlogLogger(logger *slog.Logger) ILogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &slogLogger{logger: logger}
+}
+
+// NewNopLogger cria um ILogger que descarta todas as mensagens
+func NewNopLogger() ILogger {
+	return nopLogger{}
+}
+
+// ==========================================================================
+// Métodos slogLogger
+// ==========================================================================
+
+func (l *slogLogger) Debug(msg string, args ...any) {
+	l.logger.Log(context.Background(), slog.LevelDebug, msg, args...)
+}
+
+func (l *slogLogger) Info(msg string, args ...any) {
+	l.logger.Log(context.Background(), slog.LevelInfo, msg, args...)
+}
+
+func (l *slogLogger) Warn(msg string, args ...any) {
+	l.logger.Log(context.Background(), slog.LevelWarn, msg, args...)
+}
+
+func (l *slogLogger) Error(msg string, args ...any) {
+	l.logger.Log(context.Background(), slog.LevelError, msg, args...)
+}
+
+// ==========================================================================
+// Métodos nopLogger
+// ==========================================================================
+
+func (nopLogger) Debug(string, ...any) {}
+
+func (nopLogger) Info(string, ...any) {}
+
+func (nopLogger) Warn(string, ...any) {}
+
+func (nopLogger) Error(string, ...any) {}
@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Teste do adaptador slog: mensagem, nível e campos estruturados chegam ao handler
+func TestSlogLoggerWritesStructuredFields(t *testing.T) {
+	var buffer bytes.Buffer
+	log := NewSlogLogger(slog.New(slog.NewJSONHandler(&buffer, nil)))
+
+	log.Error("Error on handle message", FieldTopic, "orders", FieldPartition, int32(2), FieldOffset, int64(42), FieldError, errors.New("boom"))
+
+	var entry map[string]any
+	assert.NoError(t, json.Unmarshal(buffer.Bytes(), &entry))
+	assert.Equal(t, "ERROR", entry["level"])
+	assert.Equal(t, "Error on handle message", entry["msg"])
+	assert.Equal(t, "orders", entry[FieldTopic])
+	assert.Equal(t, float64(2), entry[FieldPartition])
+	assert.Equal(t, float64(42), entry[FieldOffset])
+	assert.Equal(t, "boom", entry[FieldError])
+}
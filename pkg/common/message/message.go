@@ -3,14 +3,39 @@ package message
 import (
 	"errors"
 	"reflect"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// Message representa uma mensagem Kafka com payload fortemente tipado.
+//
+// Os campos de coordenadas (Topic, Partition, Offset), TimestampType e LeaderEpoch são preenchidos
+// pelo consumidor a partir do registro Kafka e ignorados na publicação. Key, TargetPartition e
+// Timestamp são respeitados pelo publicador.
 type Message[TData any] struct {
 	CorrelationId uuid.UUID
 	Data          TData
 	Metadata      map[string][]byte
+
+	// Key contém os bytes da chave do registro Kafka. Na publicação, quando vazia, o CorrelationId é usado como chave
+	Key []byte
+	// TargetPartition define a partição de destino na publicação (nil = escolhida pelo particionador)
+	TargetPartition *int32
+	// Topic é o tópico de onde a mensagem foi consumida
+	Topic string
+	// Partition é a partição de onde a mensagem foi consumida
+	Partition int32
+	// Offset é a posição da mensagem na partição
+	Offset int64
+	// Timestamp é o timestamp do registro Kafka. Na publicação, um valor diferente de zero é enviado como
+	// timestamp do registro e, quando zero, o produtor usa o horário atual. Uma mensagem consumida mantém o
+	// timestamp original: zere-o antes de republicá-la para que o novo registro receba o horário atual.
+	Timestamp time.Time
+	// TimestampType indica se o timestamp foi definido pelo produtor ou pelo broker
+	TimestampType TimestampType
+	// LeaderEpoch é a época do líder da partição no momento da leitura, quando informada pelo broker
+	LeaderEpoch *int32
 }
 
 func NewForData[TData any](correlationId uuid.UUID, data TData, metadata map[string][]byte) (Message[TData], error) {
@@ -37,11 +62,35 @@ func NewForDataWithKey[TData any](correlationId uuid.UUID, data TData, key strin
 	message := Message[TData]{}
 	message.Data = data
 	message.CorrelationId = correlationId
-	message.Metadata = make(map[string][]byte, len(metadata)+2)
-	message.Metadata[key] = []byte(key)
+	message.Key = []byte(key)
+	message.Metadata = make(map[string][]byte, len(metadata)+1)
 	message.Metadata["correlationId"] = []byte(correlationId.String())
 	for key, value := range metadata {
 		message.Metadata[key] = value
 	}
 	return message, nil
 }
+
+// WithKey retorna uma cópia da mensagem com a chave Kafka informada
+func (message Message[TData]) WithKey(key []byte) Message[TData] {
+	message.Key = key
+	return message
+}
+
+// WithStringKey retorna uma cópia da mensagem com a chave Kafka informada como texto
+func (message Message[TData]) WithStringKey(key string) Message[TData] {
+	message.Key = []byte(key)
+	return message
+}
+
+// WithPartition retorna uma cópia da mensagem direcionada para a partição informada
+func (message Message[TData]) WithPartition(partition int32) Message[TData] {
+	message.TargetPartition = &partition
+	return message
+}
+
+// WithTimestamp retorna uma cópia da mensagem com o timestamp de produção informado
+func (message Message[TData]) WithTimestamp(timestamp time.Time) Message[TData] {
+	message.Timestamp = timestamp
+	return message
+}
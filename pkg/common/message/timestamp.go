@@ -0,0 +1,19 @@
+package message
+
+// ==========================================================================
+// Tipos de Timestamp
+// ==========================================================================
+
+// TimestampType indica a origem do timestamp de uma mensagem consumida
+type TimestampType int
+
+const (
+	// TimestampNotAvailable indica que o broker não informou o timestamp da mensagem
+	TimestampNotAvailable TimestampType = iota
+
+	// TimestampCreateTime indica que o timestamp foi definido pelo produtor na criação da mensagem
+	TimestampCreateTime
+
+	// TimestampLogAppendTime indica que o timestamp foi definido pelo broker ao gravar a mensagem no log
+	TimestampLogAppendTime
+)
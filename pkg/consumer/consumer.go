@@ -3,117 +3,190 @@ package consumer
 import (
 	"context"
 	"fmt"
-	"reflect"
-	"sync"
+	"time"
 
 	engine "github.com/Dieg657/kafka-toolkit-lib/internal/engine/consumer"
 	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
 	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/publisher"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 )
 
 // ==========================================================================
 // Tipos e Propriedades
 // ==========================================================================
 
-// concreteConsumer implementa um consumidor thread-safe para mensagens Kafka
-// utilizando padrão singleton para cada combinação de tipo de dados e tópico
-type concreteConsumer[TData any] struct {
-	ctx       context.Context // Contexto para operações assíncronas
-	consumers sync.Map        // Cache de consumidores por tópico
+// BatchOptions define os limites de tamanho, bytes e tempo de espera que disparam a entrega de um lote
+type BatchOptions = engine.BatchOptions
+
+// BatchFailure indica quais mensagens de um lote falharam no handler de ConsumeBatch
+type BatchFailure = engine.BatchFailure
+
+// DeserializationError é retornado pelo consumo quando a estratégia de deserialização encerra o consumidor
+type DeserializationError = engine.DeserializationError
+
+// Outcome indica o destino de uma mensagem tratada por ConsumeWithOutcome
+type Outcome = engine.Outcome
+
+// Ack confirma a mensagem
+func Ack() Outcome {
+	return engine.Ack()
+}
+
+// Retry entrega a mensagem novamente após o intervalo, pausando apenas a sua partição
+func Retry(after time.Duration) Outcome {
+	return engine.Retry(after)
+}
+
+// DeadLetter encaminha a mensagem ao tópico de dead-letter com o motivo informado
+func DeadLetter(reason string) Outcome {
+	return engine.DeadLetter(reason)
+}
+
+// Skip segue para a próxima mensagem sem confirmar esta
+func Skip() Outcome {
+	return engine.Skip()
+}
+
+// MessageInfo reúne os metadados da mensagem disponíveis no contexto de ConsumeMessageWithContext
+type MessageInfo = engine.MessageInfo
+
+// ErrHandlerAborted indica que o watchdog abandonou um handler que excedeu o prazo da mensagem
+var ErrHandlerAborted = engine.ErrHandlerAborted
+
+// MessageInfoFromContext retorna os metadados da mensagem do contexto recebido pelo handler de ConsumeMessageWithContext
+func MessageInfoFromContext(ctx context.Context) (MessageInfo, bool) {
+	return engine.MessageInfoFromContext(ctx)
 }
 
-// Mapa global thread-safe para armazenar instâncias singleton por tipo+tópico
-var consumerRegistry sync.Map
+// NewBatchFailure cria uma falha parcial de lote para os índices das mensagens que falharam
+func NewBatchFailure(err error, failed ...int) *BatchFailure {
+	return engine.NewBatchFailure(err, failed...)
+}
 
 // ==========================================================================
 // Métodos Públicos
 // ==========================================================================
 
 // ConsumeMessage implementa o método da interface IConsumer
-// Recebe um tópico e formato, criando ou reutilizando um consumidor existente
-// para o par tipo+tópico, e inicia o consumo de mensagens.
-func ConsumeMessage[TData any](ctx context.Context, topic string, format enums.Deserialization, strategy enums.DeserializationStrategy, handler func(message message.Message[TData]) error) error {
+// Recebe um tópico e formato, cria um consumidor Kafka exclusivo para a assinatura
+// e inicia o consumo de mensagens.
+// O tópico pode ser uma expressão regular iniciada por "^", e WithTopics assina tópicos adicionais.
+// As opções permitem configurar retentativas e o tópico de dead-letter.
+func ConsumeMessage[TData any](ctx context.Context, topic string, format enums.Deserialization, strategy enums.DeserializationStrategy, handler func(message message.Message[TData]) error, opts ...Option) error {
 	engineConsumer, err := getEngineConsumer[TData](ctx, topic)
 	if err != nil {
 		return fmt.Errorf("falha ao preparar consumidor para tópico %s: %w", topic, err)
 	}
 
 	// Inicia o consumo usando o engine consumer
-	return engineConsumer.Consume(topic, format, strategy, handler)
+	return engineConsumer.Consume(topic, format, strategy, buildOptions(opts), handler)
 }
 
-func ConsumeMessageInParallel[TData any](ctx context.Context, topic string, format enums.Deserialization, strategy enums.DeserializationStrategy, handler func(message message.Message[TData]) error) error {
+// ConsumeMessageWithContext implementa o método da interface IConsumer
+// Consome o tópico sequencialmente com um handler que recebe um contexto por mensagem. O contexto deriva
+// de ctx, é cancelado no encerramento do consumidor, expira no prazo da mensagem (WithHandlerTimeout) e
+// carrega os metadados da mensagem, obtidos com MessageInfoFromContext. Um watchdog registra os handlers
+// que excedem o prazo e, com WithWatchdog(enums.WatchdogAbort), abandona-os antes de exceder max.poll.interval.ms.
+func ConsumeMessageWithContext[TData any](ctx context.Context, topic string, format enums.Deserialization, strategy enums.DeserializationStrategy, handler func(ctx context.Context, message message.Message[TData]) error, opts ...Option) error {
 	engineConsumer, err := getEngineConsumer[TData](ctx, topic)
 	if err != nil {
 		return fmt.Errorf("falha ao preparar consumidor para tópico %s: %w", topic, err)
 	}
 
-	// Inicia o consumo usando o engine consumer
-	return engineConsumer.Consume(topic, format, strategy, handler)
+	// Inicia o consumo com contexto por mensagem usando o engine consumer
+	return engineConsumer.ConsumeWithContext(topic, format, strategy, buildOptions(opts), handler)
 }
 
-// ==========================================================================
-// Métodos Privados
-// ==========================================================================
+// ConsumeWithOutcome implementa o método da interface IConsumer
+// Consome o tópico sequencialmente com um handler que retorna o destino de cada mensagem:
+// Ack confirma, Retry(after) a entrega novamente após o intervalo sem bloquear as demais partições,
+// DeadLetter(reason) a encaminha ao tópico de dead-letter e Skip segue sem confirmá-la.
+// WithRetryPolicy e WithRetryTopics não se aplicam, pois o handler decide quando repetir.
+func ConsumeWithOutcome[TData any](ctx context.Context, topic string, format enums.Deserialization, strategy enums.DeserializationStrategy, handler func(message message.Message[TData]) Outcome, opts ...Option) error {
+	engineConsumer, err := getEngineConsumer[TData](ctx, topic)
+	if err != nil {
+		return fmt.Errorf("falha ao preparar consumidor para tópico %s: %w", topic, err)
+	}
 
-// getEngineConsumer
-func getEngineConsumer[TData any](ctx context.Context, topic string) (engine.IKafkaConsumer[TData], error) {
-	// Gera uma chave única baseada no tipo e tópico
-	key := fmt.Sprintf("%s:%s", getTypeName[TData](), topic)
-	// Verifica se já existe um consumer para este par tipo+tópico
-	var consumer *concreteConsumer[TData]
-	if existingConsumer, exists := consumerRegistry.Load(key); exists {
-		if typedConsumer, ok := existingConsumer.(*concreteConsumer[TData]); ok {
-			consumer = typedConsumer
-		}
+	// Inicia o consumo com destino explícito usando o engine consumer
+	return engineConsumer.ConsumeWithOutcome(topic, format, strategy, buildOptions(opts), handler)
+}
+
+// ConsumeMessageInParallel implementa o método da interface IConsumer
+// Distribui as mensagens do tópico entre parallelWorkers workers, cada um com uma fila
+// de até batchSize mensagens. Mensagens com a mesma chave (ou da mesma partição, conforme
+// o ordering) são processadas em ordem pelo mesmo worker. Se uma mensagem não puder ser tratada
+// nem encaminhada à dead-letter, a partição volta a ser consumida a partir dela.
+func ConsumeMessageInParallel[TData any](ctx context.Context, parallelWorkers int, batchSize int, ordering enums.ParallelOrdering, topic string, format enums.Deserialization, strategy enums.DeserializationStrategy, handler func(message message.Message[TData]) error, opts ...Option) error {
+	engineConsumer, err := getEngineConsumer[TData](ctx, topic)
+	if err != nil {
+		return fmt.Errorf("falha ao preparar consumidor para tópico %s: %w", topic, err)
 	}
-	// Se não existe, cria um novo
-	if consumer == nil {
-		consumer = &concreteConsumer[TData]{
-			ctx: ctx,
-		}
-		// Armazena o consumidor no registro global, garantindo concorrência segura
-		actualConsumer, loaded := consumerRegistry.LoadOrStore(key, consumer)
-		if loaded {
-			// Se outro thread criou o consumidor enquanto estávamos criando, usa o existente
-			if typedConsumer, ok := actualConsumer.(*concreteConsumer[TData]); ok {
-				consumer = typedConsumer
-			}
-		}
+
+	parallelism := engine.ParallelOptions{
+		Workers:   parallelWorkers,
+		QueueSize: batchSize,
+		Ordering:  ordering,
 	}
-	// Obtém ou cria um engine.Consumer específico para este tópico
-	engineConsumer, err := consumer.getOrCreateConsumer(topic)
 
-	return engineConsumer, err
+	// Inicia o consumo paralelo usando o engine consumer
+	return engineConsumer.ConsumeInParallel(topic, format, strategy, parallelism, buildOptions(opts), handler)
 }
 
-// getOrCreateConsumer obtém ou cria um consumidor específico para um tópico
-func (c *concreteConsumer[TData]) getOrCreateConsumer(topic string) (engine.IKafkaConsumer[TData], error) {
-	// Tenta obter do cache
-	if instance, exists := c.consumers.Load(topic); exists {
-		if consumer, ok := instance.(engine.IKafkaConsumer[TData]); ok {
-			return consumer, nil
-		}
+// ConsumeBatch implementa o método da interface IConsumer
+// Entrega as mensagens do tópico ao handler em lotes, de acordo com os limites informados.
+// Os offsets do lote só são confirmados quando o handler retorna nil. Para falhas parciais o
+// handler deve retornar um *BatchFailure com os índices das mensagens que falharam.
+func ConsumeBatch[TData any](ctx context.Context, topic string, format enums.Deserialization, strategy enums.DeserializationStrategy, options BatchOptions, handler func(messages []message.Message[TData]) error, opts ...Option) error {
+	engineConsumer, err := getEngineConsumer[TData](ctx, topic)
+	if err != nil {
+		return fmt.Errorf("falha ao preparar consumidor para tópico %s: %w", topic, err)
 	}
 
-	// Cria novo consumidor
-	consumer, err := engine.NewKafkaConsumer[TData](c.ctx)
+	// Inicia o consumo em lotes usando o engine consumer
+	return engineConsumer.ConsumeBatch(topic, format, strategy, options, buildOptions(opts), handler)
+}
+
+// ConsumeTransactionally implementa o método da interface IConsumer
+// Consome o tópico no padrão consume-transform-produce com exactly-once: para cada mensagem é aberta
+// uma transação, entregue ao handler para publicar com publisher.PublishInTransaction, e o offset da
+// mensagem é confirmado na mesma transação. Se o handler retornar erro a transação é abortada.
+// Requer KAFKA_TRANSACTIONAL_ID.
+func ConsumeTransactionally[TData any](ctx context.Context, topic string, format enums.Deserialization, strategy enums.DeserializationStrategy, handler func(tx *publisher.Transaction, message message.Message[TData]) error, opts ...Option) error {
+	engineConsumer, err := getEngineConsumer[TData](ctx, topic)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao criar consumidor para tópico %s: %w", topic, err)
+		return fmt.Errorf("falha ao preparar consumidor para tópico %s: %w", topic, err)
 	}
 
-	// Armazena no cache de forma thread-safe
-	actual, loaded := c.consumers.LoadOrStore(topic, consumer)
-	if loaded {
-		if typedConsumer, ok := actual.(engine.IKafkaConsumer[TData]); ok {
-			return typedConsumer, nil
-		}
-	}
+	// Inicia o consumo transacional usando o engine consumer
+	return engineConsumer.ConsumeTransactionally(topic, format, strategy, buildOptions(opts), handler)
+}
 
-	return consumer, nil
+// Commit confirma, em uma assinatura com WithCommitMode(enums.CommitManual), as mensagens informadas:
+// é confirmado o offset seguinte a cada uma. Pode ser chamado do handler ou de outra goroutine, por
+// exemplo quando o processamento termina de forma assíncrona. Retorna ErrNilController quando a
+// assinatura não recebeu um Controller (WithController).
+func Commit[TData any](controller *Controller, messages ...message.Message[TData]) error {
+	positions := make([]kafka.TopicPartition, 0, len(messages))
+	for _, msg := range messages {
+		topic := msg.Topic
+		positions = append(positions, kafka.TopicPartition{Topic: &topic, Partition: msg.Partition, Offset: kafka.Offset(msg.Offset)})
+	}
+	return controller.Commit(positions...)
 }
 
-// getTypeName retorna o nome do tipo genérico
-func getTypeName[TData any]() string {
-	return reflect.TypeOf((*TData)(nil)).Elem().String()
+// ==========================================================================
+// Métodos Privados
+// ==========================================================================
+
+// getEngineConsumer cria o consumidor do engine de uma assinatura.
+// Cada assinatura recebe uma instância própria, com seu próprio consumidor Kafka e laço de poll.
+func getEngineConsumer[TData any](ctx context.Context, topic string) (engine.IKafkaConsumer[TData], error) {
+	consumer, err := engine.NewKafkaConsumer[TData](ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar consumidor para tópico %s: %w", topic, err)
+	}
+
+	return consumer, nil
 }
@@ -0,0 +1,68 @@
+package consumer
+
+import (
+	"database/sql"
+	"time"
+
+	engine "github.com/Dieg657/kafka-toolkit-lib/internal/engine/consumer"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// IDeduplicationStore registra as chaves das mensagens já processadas por uma assinatura.
+// Implementações devem ser seguras para uso concorrente.
+type IDeduplicationStore = engine.IDeduplicationStore
+
+// DeduplicationKey define de onde a chave de deduplicação de uma mensagem é extraída
+type DeduplicationKey = engine.DeduplicationKey
+
+// SQLDeduplicationOptions configura o store de deduplicação em banco de dados
+type SQLDeduplicationOptions = engine.SQLDeduplicationOptions
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// DeduplicateByCorrelationId usa o cabeçalho correlationId como chave de deduplicação
+func DeduplicateByCorrelationId() DeduplicationKey {
+	return engine.DeduplicateByCorrelationId()
+}
+
+// DeduplicateByHeader usa o valor do cabeçalho informado como chave de deduplicação
+func DeduplicateByHeader(name string) DeduplicationKey {
+	return engine.DeduplicateByHeader(name)
+}
+
+// DeduplicateByKey usa a chave do registro Kafka como chave de deduplicação
+func DeduplicateByKey() DeduplicationKey {
+	return engine.DeduplicateByKey()
+}
+
+// NewMemoryDeduplicationStore cria um store de deduplicação em memória com política LRU e TTL.
+// Com capacity <= 0 são mantidas até 100000 chaves; com ttl <= 0 as chaves não expiram.
+func NewMemoryDeduplicationStore(capacity int, ttl time.Duration) IDeduplicationStore {
+	return engine.NewMemoryDeduplicationStore(capacity, ttl)
+}
+
+// NewSQLDeduplicationStore cria um store de deduplicação em uma tabela acessada por database/sql,
+// compartilhável entre as instâncias de um mesmo grupo de consumidores
+func NewSQLDeduplicationStore(db *sql.DB, options SQLDeduplicationOptions) (IDeduplicationStore, error) {
+	return engine.NewSQLDeduplicationStore(db, options)
+}
+
+// ==========================================================================
+// Opções
+// ==========================================================================
+
+// WithDeduplication confirma sem chamar o handler as mensagens cuja chave já foi processada com sucesso.
+// A chave é registrada no store após o handler retornar nil (ou Ack em ConsumeWithOutcome); mensagens
+// sem a chave são sempre processadas. Cada grupo de consumidores deve usar o seu próprio store ou tabela.
+// Aplica-se a todos os modos de consumo. Em ConsumeBatch as duplicatas são retiradas do lote e as chaves das
+// mensagens que não falharam são registradas; em ConsumeTransactionally a chave é registrada após a confirmação da transação.
+func WithDeduplication(store IDeduplicationStore, key DeduplicationKey) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.Deduplication = engine.DeduplicationOptions{Store: store, Key: key}
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
 	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/publisher"
 )
 
 // ==========================================================================
@@ -15,8 +16,21 @@ import (
 // O parâmetro genérico TData define o tipo de dado que será consumido
 type IConsumer[TData any] interface {
 	// ConsumeMessage inicia o consumo de mensagens de um tópico, com o formato especificado pelo handler
-	ConsumeMessage(ctx context.Context, topic string, format enums.Deserialization, strategy enums.DeserializationStrategy, handler func(message message.Message[TData]) error) error
-	// ConsumeMessageInParallel inicia o consumo de mensagens de um tópico, utilizando múltiplos workers paralelos
-	// e um tamanho de lote para otimizar o consumo de mensagens
-	ConsumeMessageInParallel(ctx context.Context, parallelWorkers int, batchSize int, topic string, format enums.Deserialization, strategy enums.DeserializationStrategy, handler func(message message.Message[TData]) error) error
+	ConsumeMessage(ctx context.Context, topic string, format enums.Deserialization, strategy enums.DeserializationStrategy, handler func(message message.Message[TData]) error, opts ...Option) error
+	// ConsumeMessageWithContext inicia o consumo de mensagens de um tópico com um handler que recebe um contexto
+	// por mensagem, com o prazo da mensagem e os seus metadados
+	ConsumeMessageWithContext(ctx context.Context, topic string, format enums.Deserialization, strategy enums.DeserializationStrategy, handler func(ctx context.Context, message message.Message[TData]) error, opts ...Option) error
+	// ConsumeWithOutcome inicia o consumo de mensagens de um tópico com um handler que retorna o destino
+	// de cada mensagem: Ack, Retry, DeadLetter ou Skip
+	ConsumeWithOutcome(ctx context.Context, topic string, format enums.Deserialization, strategy enums.DeserializationStrategy, handler func(message message.Message[TData]) Outcome, opts ...Option) error
+	// ConsumeMessageInParallel inicia o consumo de mensagens de um tópico, utilizando múltiplos workers paralelos.
+	// O batchSize define quantas mensagens cada worker pode manter enfileiradas e o ordering define
+	// se a ordem é preservada por chave ou por partição
+	ConsumeMessageInParallel(ctx context.Context, parallelWorkers int, batchSize int, ordering enums.ParallelOrdering, topic string, format enums.Deserialization, strategy enums.DeserializationStrategy, handler func(message message.Message[TData]) error, opts ...Option) error
+	// ConsumeBatch inicia o consumo de mensagens de um tópico, entregando-as ao handler em lotes
+	// limitados por tamanho, bytes e tempo máximo de espera
+	ConsumeBatch(ctx context.Context, topic string, format enums.Deserialization, strategy enums.DeserializationStrategy, options BatchOptions, handler func(messages []message.Message[TData]) error, opts ...Option) error
+	// ConsumeTransactionally inicia o consumo transacional: as publicações feitas pelo handler na transação
+	// e o offset da mensagem consumida são confirmados atomicamente
+	ConsumeTransactionally(ctx context.Context, topic string, format enums.Deserialization, strategy enums.DeserializationStrategy, handler func(tx *publisher.Transaction, message message.Message[TData]) error, opts ...Option) error
 }
@@ -0,0 +1,319 @@
+package consumer
+
+import (
+	"time"
+
+	internalEnums "github.com/Dieg657/kafka-toolkit-lib/internal/common/enums"
+	engine "github.com/Dieg657/kafka-toolkit-lib/internal/engine/consumer"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/middleware"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// Option configura um comportamento opcional de uma assinatura
+type Option func(options *engine.ConsumeOptions)
+
+// RetryPolicy define as retentativas em memória com backoff exponencial e jitter
+// aplicadas quando o handler retorna erro
+type RetryPolicy = engine.RetryPolicy
+
+// Controller controla o ciclo de vida de uma assinatura: Close encerra o consumo de forma ordenada,
+// aguardando os handlers em andamento, confirmando os offsets finais e deixando o grupo.
+// Pause e Resume interrompem e retomam a busca de mensagens sem deixar o grupo, e Seek reposiciona partições.
+type Controller = engine.Controller
+
+// RebalanceHooks reúne as funções chamadas na atribuição, revogação e perda de partições
+type RebalanceHooks = engine.RebalanceHooks
+
+// ErrControllerInUse indica que o controlador já está associado a uma assinatura
+var ErrControllerInUse = engine.ErrControllerInUse
+
+// ErrSubscriptionNotRunning é retornado por Controller.Pause e Controller.Resume quando a assinatura
+// não está em execução
+var ErrSubscriptionNotRunning = engine.ErrSubscriptionNotRunning
+
+// ErrManualCommitDisabled é retornado por Commit quando a assinatura não utiliza o commit manual
+var ErrManualCommitDisabled = engine.ErrManualCommitDisabled
+
+// ErrNilController é retornado por Commit e pelos métodos do Controller quando o controlador é nil
+var ErrNilController = engine.ErrNilController
+
+// BackpressureOptions define os limites de mensagens em processamento que pausam e retomam o consumo paralelo
+type BackpressureOptions = engine.BackpressureOptions
+
+// Cabeçalhos adicionados às mensagens encaminhadas para o tópico de dead-letter
+const (
+	DeadLetterHeaderError     = engine.DeadLetterHeaderError
+	DeadLetterHeaderTopic     = engine.DeadLetterHeaderTopic
+	DeadLetterHeaderPartition = engine.DeadLetterHeaderPartition
+	DeadLetterHeaderOffset    = engine.DeadLetterHeaderOffset
+	DeadLetterHeaderAttempts  = engine.DeadLetterHeaderAttempts
+)
+
+// Cabeçalhos adicionados às mensagens republicadas nos tópicos de retentativa
+const (
+	RetryHeaderOriginalTopic     = engine.RetryHeaderOriginalTopic
+	RetryHeaderOriginalPartition = engine.RetryHeaderOriginalPartition
+	RetryHeaderOriginalOffset    = engine.RetryHeaderOriginalOffset
+	RetryHeaderAttempt           = engine.RetryHeaderAttempt
+	RetryHeaderDueAt             = engine.RetryHeaderDueAt
+	RetryHeaderError             = engine.RetryHeaderError
+)
+
+// ==========================================================================
+// Opções
+// ==========================================================================
+
+// WithGroupId define o grupo de consumidores da assinatura, substituindo KAFKA_GROUPID.
+// Permite vários grupos no mesmo processo, por exemplo um grupo de projeção e outro de auditoria
+// no mesmo tópico, ou um grupo exclusivo por instância para consumo em broadcast.
+func WithGroupId(groupId string) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.GroupId = groupId
+	}
+}
+
+// WithAutoOffsetReset define onde a assinatura começa quando o grupo não possui offset confirmado,
+// substituindo KAFKA_AUTO_OFFSET_RESET e o valor definido pela prioridade do consumidor.
+func WithAutoOffsetReset(reset enums.AutoOffsetReset) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.AutoOffsetReset = reset
+	}
+}
+
+// WithPriority define a prioridade da assinatura, substituindo KAFKA_CONSUMER_PRIORITY.
+// A prioridade determina as configurações de busca, sessão e isolamento do consumidor.
+func WithPriority(priority enums.ConsumerPriority) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.Priority = priority
+	}
+}
+
+// WithCommitMode define como os offsets das mensagens tratadas são confirmados:
+//   - enums.CommitPerMessage: commit síncrono após cada mensagem (ou lote)
+//   - enums.CommitPeriodic (padrão): os offsets tratados são armazenados e confirmados de forma assíncrona a cada intervalo
+//   - enums.CommitManual: apenas as mensagens informadas em Commit são confirmadas; exige WithController
+//
+// Em todos os modos um commit síncrono final é feito na revogação das partições e no encerramento.
+func WithCommitMode(mode enums.CommitMode) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.CommitMode = mode
+	}
+}
+
+// WithCommitInterval define o intervalo do commit periódico, substituindo o auto.commit.interval.ms da prioridade
+func WithCommitInterval(interval time.Duration) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.CommitInterval = interval
+	}
+}
+
+// WithAssignmentStrategy define o partition.assignment.strategy da assinatura, substituindo
+// KAFKA_PARTITION_ASSIGNMENT_STRATEGY. Com enums.AssignmentCooperativeSticky o grupo usa o protocolo
+// cooperativo: apenas as partições que mudam de dono são revogadas no rebalanceamento.
+func WithAssignmentStrategy(strategy enums.AssignmentStrategy) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.AssignmentStrategy = strategy
+	}
+}
+
+// WithOnPartitionsAssigned define a função chamada quando partições são atribuídas à assinatura.
+// É executada antes da entrega da primeira mensagem das partições, permitindo preparar o estado
+// por partição usado pelo handler.
+func WithOnPartitionsAssigned(hook func(partitions []kafka.TopicPartition)) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.Rebalance.OnAssigned = hook
+	}
+}
+
+// WithOnPartitionsRevoked define a função chamada quando partições são revogadas no rebalanceamento.
+// Os offsets das mensagens já processadas dessas partições são confirmados antes da chamada.
+func WithOnPartitionsRevoked(hook func(partitions []kafka.TopicPartition)) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.Rebalance.OnRevoked = hook
+	}
+}
+
+// WithOnPartitionsLost define a função chamada quando partições são perdidas involuntariamente,
+// por exemplo após exceder max.poll.interval.ms. Nesse caso os offsets não são confirmados.
+func WithOnPartitionsLost(hook func(partitions []kafka.TopicPartition)) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.Rebalance.OnLost = hook
+	}
+}
+
+// WithStartOffsets inicia as partições informadas no offset de cada uma, ignorando o offset confirmado
+// do grupo. A posição vale apenas na primeira atribuição de cada partição à assinatura.
+func WithStartOffsets(offsets ...kafka.TopicPartition) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.StartPosition.Offsets = append(options.StartPosition.Offsets, offsets...)
+	}
+}
+
+// WithStartTimestamp inicia cada partição no primeiro offset com timestamp maior ou igual ao informado,
+// consultado com OffsetsForTimes. Partições sem mensagens posteriores iniciam pelo fim.
+func WithStartTimestamp(timestamp time.Time) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.StartPosition.Timestamp = timestamp
+	}
+}
+
+// WithStartFromBeginning inicia cada partição pela mensagem mais antiga disponível, ignorando o offset
+// confirmado do grupo (equivale a KAFKA_AUTO_OFFSET_RESET=BEGINNING aplicado mesmo com offset confirmado)
+func WithStartFromBeginning() Option {
+	return func(options *engine.ConsumeOptions) {
+		options.StartPosition.Reset = internalEnums.OFFSET_RESET_BEGINNING
+	}
+}
+
+// WithStartFromEnd inicia cada partição pelo fim, consumindo apenas mensagens novas e ignorando o offset
+// confirmado do grupo (equivale a KAFKA_AUTO_OFFSET_RESET=END aplicado mesmo com offset confirmado)
+func WithStartFromEnd() Option {
+	return func(options *engine.ConsumeOptions) {
+		options.StartPosition.Reset = internalEnums.OFFSET_RESET_END
+	}
+}
+
+// WithTopics assina, na mesma assinatura e com o mesmo handler, os tópicos informados além do tópico principal.
+// Nomes iniciados por "^" são expressões regulares, por exemplo WithTopics(`^orders\..*`, "payments").
+// O tópico de cada mensagem fica disponível em message.Topic. Tópicos de retentativa não podem ser
+// combinados com expressões regulares.
+func WithTopics(topics ...string) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.Topics = append(options.Topics, topics...)
+	}
+}
+
+// WithPartitions atribui manualmente as partições informadas de cada tópico da assinatura, sem
+// participar do rebalanceamento do grupo. Sem WithGroupId a assinatura usa um grupo efêmero e não
+// confirma offsets: cada partição começa pela posição de WithStart* ou pelo auto.offset.reset.
+// Com WithGroupId o consumo continua do offset confirmado do grupo e os offsets processados são confirmados.
+func WithPartitions(partitions ...int32) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.Partitions = append(options.Partitions, partitions...)
+	}
+}
+
+// WithBounded encerra o consumo quando todas as partições atribuídas alcançam o offset final que
+// tinham na atribuição, consultado pela marca d'água superior. A função de consumo retorna nil após
+// confirmar os offsets processados, permitindo reprocessamentos e jobs que leem um tópico até o fim.
+func WithBounded() Option {
+	return func(options *engine.ConsumeOptions) {
+		options.Bounded = true
+	}
+}
+
+// WithMiddleware envolve cada execução do handler com os middlewares informados, na ordem em que são
+// registrados: o primeiro é o mais externo. Chamadas sucessivas acrescentam middlewares à cadeia.
+// Aplica-se a ConsumeMessage, ConsumeMessageInParallel, ConsumeWithOutcome e ConsumeTransactionally;
+// cada retentativa da RetryPolicy passa novamente pela cadeia. Erros retornados pelos middlewares
+// são tratados como erros do handler.
+func WithMiddleware(middlewares ...middleware.Middleware) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.Middlewares = append(options.Middlewares, middlewares...)
+	}
+}
+
+// WithHandlerTimeout define o prazo de cada mensagem do handler de ConsumeMessageWithContext, refletido
+// no contexto recebido pelo handler e compartilhado pelas retentativas da WithRetryPolicy. O padrão, e
+// também o limite, é 80% do max.poll.interval.ms da assinatura.
+func WithHandlerTimeout(timeout time.Duration) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.HandlerTimeout = timeout
+	}
+}
+
+// WithWatchdog define o que fazer quando o handler de ConsumeMessageWithContext excede o prazo:
+// WatchdogReport (padrão) registra em log e aguarda; WatchdogAbort abandona o handler e trata a
+// mensagem como falha, com ErrHandlerAborted. A mensagem abandonada não é repetida pela RetryPolicy e
+// segue direto para o tópico de retentativa ou a dead-letter; como o handler abandonado não é interrompido,
+// o reprocessamento por esses tópicos ainda pode coincidir com ele.
+func WithWatchdog(action enums.WatchdogAction) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.Watchdog = action
+	}
+}
+
+// WithRetryPolicy repete o handler conforme a política informada antes de considerar a mensagem falha
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.RetryPolicy = policy
+	}
+}
+
+// WithDeadLetterTopic encaminha para o tópico informado as mensagens que esgotaram as tentativas, as que
+// não puderam ser deserializadas (OnDeserializationFailedSendToDeadLetter) e as rejeitadas com DeadLetter.
+// Sem esta opção a dead-letter é "<tópico da mensagem>.dlq". A mensagem é publicada com os bytes e
+// cabeçalhos originais, usando o produtor do container IoC; se o encaminhamento falhar, ou não for confirmado
+// dentro do message.timeout.ms do produtor, ela não é confirmada.
+func WithDeadLetterTopic(topic string) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.DeadLetterTopic = topic
+	}
+}
+
+// WithRetryTopics habilita retentativas sem bloqueio da partição por meio de tópicos de retentativa.
+// Uma mensagem falha é republicada em <tópico>.retry.<atraso> para cada atraso informado, em ordem,
+// por exemplo WithRetryTopics(time.Minute, 10*time.Minute) usa "<tópico>.retry.1m" e "<tópico>.retry.10m".
+// Cada nível é consumido por um cliente próprio, no grupo "<grupo>.retry.<atraso>", que pausa cada partição
+// até o vencimento da próxima mensagem e invoca o mesmo handler sem reter a assinatura principal. Após o
+// último nível a mensagem segue para o tópico configurado em WithDeadLetterTopic ou, se ausente, para "<tópico>.dlq".
+// Os atrasos devem ser positivos e distintos; caso contrário a assinatura retorna erro.
+func WithRetryTopics(delays ...time.Duration) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.RetryTopicDelays = delays
+	}
+}
+
+// WithDeserializationFailureCallback define o callback usado pela estratégia OnDeserializationFailedInvokeCallback.
+// O callback recebe a mensagem Kafka original e o *DeserializationError; retornar erro encerra o consumo.
+func WithDeserializationFailureCallback(callback func(record *kafka.Message, err error) error) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.DeserializationFailureCallback = callback
+	}
+}
+
+// WithBackpressure define os limites de mensagens em processamento do consumo paralelo: ao atingir
+// high todas as partições são pausadas, sem deixar o grupo, e o consumo é retomado ao chegar a low.
+// Por padrão high é workers × batchSize e low é metade de high.
+func WithBackpressure(high, low int) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.Backpressure = BackpressureOptions{HighWatermark: high, LowWatermark: low}
+	}
+}
+
+// WithController associa a assinatura ao controlador informado, permitindo encerrá-la com controller.Close()
+// e pausar ou retomar partições com controller.Pause e controller.Resume.
+// Cada controlador pode ser usado por uma única assinatura.
+func WithController(controller *Controller) Option {
+	return func(options *engine.ConsumeOptions) {
+		options.Controller = controller
+	}
+}
+
+// NewController cria um controlador de assinatura para uso com WithController
+func NewController() *Controller {
+	return engine.NewController()
+}
+
+// RetryTopicName retorna o nome do tópico de retentativa de um tópico para o atraso informado
+func RetryTopicName(topic string, delay time.Duration) string {
+	return engine.RetryTopicName(topic, delay)
+}
+
+// ==========================================================================
+// Métodos Privados
+// ==========================================================================
+
+// buildOptions aplica as opções informadas sobre a configuração padrão
+func buildOptions(opts []Option) engine.ConsumeOptions {
+	options := engine.ConsumeOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"time"
+
+	engine "github.com/Dieg657/kafka-toolkit-lib/internal/engine/middleware"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// Handler processa um registro Kafka. No consumo é o handler da aplicação para a mensagem consumida;
+// na publicação é o enfileiramento do registro no produtor.
+type Handler = engine.Handler
+
+// Middleware envolve um Handler com um comportamento transversal, como medição de tempo,
+// rastreamento, enriquecimento de cabeçalhos ou autorização
+type Middleware = engine.Middleware
+
+// PanicError é o erro retornado por Recover quando o handler entra em pânico, com o valor e a pilha
+type PanicError = engine.PanicError
+
+// ErrTimeout indica que o handler não terminou dentro do tempo limite de Timeout
+var ErrTimeout = engine.ErrTimeout
+
+// ==========================================================================
+// Funções Públicas
+// ==========================================================================
+
+// Chain compõe os middlewares em volta do handler; o primeiro informado é o mais externo
+func Chain(handler Handler, middlewares ...Middleware) Handler {
+	return engine.Chain(handler, middlewares...)
+}
+
+// Recover converte um pânico do handler em um *PanicError, tratado como qualquer erro do handler
+// (retentativas, tópicos de retentativa e dead-letter)
+func Recover() Middleware {
+	return engine.Recover()
+}
+
+// Timeout retorna um erro que envolve ErrTimeout quando o handler não termina dentro do tempo informado.
+// O handler não é interrompido: continua em segundo plano e o seu resultado é descartado. Por isso a falha
+// por tempo limite não é repetida pela RetryPolicy e segue direto para o tópico de retentativa ou a dead-letter.
+func Timeout(timeout time.Duration) Middleware {
+	return engine.Timeout(timeout)
+}
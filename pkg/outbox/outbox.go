@@ -0,0 +1,154 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/internal/common/sqlquery"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// Options configura a tabela de outbox
+type Options struct {
+	// Table é a tabela de outbox (padrão: kafka_outbox)
+	Table string
+	// Placeholder define o marcador de parâmetros do driver (padrão: "?")
+	Placeholder enums.SQLPlaceholder
+}
+
+// Outbox reúne as consultas da tabela de outbox usadas por Insert e pelo Relay.
+// A tabela deve existir, por exemplo (SQLite):
+//
+//	CREATE TABLE kafka_outbox (
+//	    id            INTEGER PRIMARY KEY AUTOINCREMENT, -- BIGSERIAL no PostgreSQL
+//	    aggregate_id  VARCHAR(255) NOT NULL,
+//	    message_type  VARCHAR(255) NOT NULL,
+//	    topic         VARCHAR(255) NOT NULL,
+//	    serialization INTEGER NOT NULL,
+//	    payload       TEXT NOT NULL,
+//	    created_at    BIGINT NOT NULL,
+//	    sent_at       BIGINT,
+//	    attempts      INTEGER NOT NULL DEFAULT 0,
+//	    last_error    TEXT,
+//	    failed_at     BIGINT
+//	);
+//	CREATE INDEX kafka_outbox_pending ON kafka_outbox (message_type, sent_at, id);
+//	CREATE INDEX kafka_outbox_aggregate ON kafka_outbox (message_type, aggregate_id, sent_at);
+//
+// Mensagens que esgotam as tentativas do Relay recebem failed_at e deixam de ser publicadas, assim como
+// as seguintes do mesmo agregado. Para publicá-las novamente, limpe failed_at e zere attempts.
+type Outbox struct {
+	insertQuery  string
+	pendingQuery string
+	sentQuery    string
+	failedQuery  string
+}
+
+// defaultTable é a tabela de outbox usada quando não informada
+const defaultTable = "kafka_outbox"
+
+// pendingQuery lê as mensagens pendentes que podem ser publicadas: as mensagens de um agregado com uma
+// anterior já falha ou estacionada aguardam a anterior, e as que já falharam vêm depois das novas, para
+// que mensagens problemáticas não ocupem o lote e bloqueiem os demais agregados.
+const pendingQuery = `SELECT o.id, o.aggregate_id, o.topic, o.serialization, o.payload, o.attempts FROM %[1]s o
+WHERE o.message_type = ? AND o.sent_at IS NULL AND o.failed_at IS NULL
+AND NOT EXISTS (
+	SELECT 1 FROM %[1]s p
+	WHERE p.message_type = o.message_type AND p.aggregate_id = o.aggregate_id AND p.aggregate_id <> ''
+	AND p.id < o.id AND p.sent_at IS NULL AND p.attempts > 0
+)
+ORDER BY o.attempts, o.id LIMIT ?`
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// New valida a configuração e prepara as consultas da tabela de outbox.
+//
+// Parâmetros:
+//   - options: Tabela e marcador de parâmetros do driver
+//
+// Retorno:
+//   - *Outbox: Outbox usado por Insert e NewRelay
+//   - error: Erro caso o nome da tabela seja inválido
+func New(options Options) (*Outbox, error) {
+	table := options.Table
+	if table == "" {
+		table = defaultTable
+	}
+	if err := sqlquery.ValidateTable(table); err != nil {
+		return nil, err
+	}
+
+	rebind := func(query string) string {
+		return sqlquery.Rebind(options.Placeholder, fmt.Sprintf(query, table))
+	}
+
+	return &Outbox{
+		insertQuery:  rebind("INSERT INTO %s (aggregate_id, message_type, topic, serialization, payload, created_at) VALUES (?, ?, ?, ?, ?, ?)"),
+		pendingQuery: rebind(pendingQuery),
+		sentQuery:    rebind("UPDATE %s SET sent_at = ?, attempts = attempts + 1, last_error = NULL WHERE id = ?"),
+		failedQuery:  rebind("UPDATE %s SET attempts = attempts + 1, last_error = ?, failed_at = ? WHERE id = ?"),
+	}, nil
+}
+
+// ==========================================================================
+// Funções Públicas
+// ==========================================================================
+
+// Insert grava a mensagem na tabela de outbox dentro da transação do chamador, junto com os dados de
+// negócio. A mensagem só é publicada pelo Relay depois do commit da transação. Mensagens do mesmo
+// agregado são publicadas na ordem de inserção.
+//
+// A mensagem é armazenada em JSON e serializada no formato informado apenas na publicação,
+// portanto TData deve ser representável em JSON sem perda.
+//
+// Parâmetros:
+//   - ctx: Contexto da operação no banco de dados
+//   - tx: Transação do chamador
+//   - box: Outbox criado com New
+//   - aggregateId: Identificador do agregado, que define a ordem de publicação
+//   - topic: Tópico Kafka de destino
+//   - message: Mensagem tipada a ser publicada
+//   - serialization: Formato de serialização usado na publicação
+//
+// Retorno:
+//   - error: Erro caso a mensagem não possa ser codificada ou gravada
+func Insert[TData any](ctx context.Context, tx *sql.Tx, box *Outbox, aggregateId string, topic string, message message.Message[TData], serialization enums.Serialization) error {
+	if tx == nil {
+		return errors.New("transação não informada para gravar no outbox")
+	}
+	if topic == "" {
+		return errors.New("tópico não informado para gravar no outbox")
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("falha ao codificar mensagem do outbox: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, box.insertQuery, aggregateId, typeName[TData](), topic, int(serialization), string(payload), time.Now().UnixMilli())
+	if err != nil {
+		return fmt.Errorf("falha ao gravar mensagem no outbox: %w", err)
+	}
+	return nil
+}
+
+// ==========================================================================
+// Funções Privadas
+// ==========================================================================
+
+// typeName retorna o nome do tipo TData, que identifica as mensagens de cada Relay
+func typeName[TData any]() string {
+	return reflect.TypeOf((*TData)(nil)).Elem().String()
+}
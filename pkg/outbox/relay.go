@@ -0,0 +1,335 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/constants"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/ioc"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/middleware"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/publisher"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// RelayOptions configura a leitura e a publicação das mensagens pendentes
+type RelayOptions struct {
+	// PollInterval é o intervalo entre as consultas quando não há mensagens pendentes (padrão: 1s)
+	PollInterval time.Duration
+	// BatchSize é a quantidade máxima de mensagens lidas por consulta (padrão: 100)
+	BatchSize int
+	// Concurrency é a quantidade de agregados publicados simultaneamente (padrão: 8)
+	Concurrency int
+	// MaxAttempts é a quantidade de tentativas de publicação de uma mensagem antes de estacioná-la (padrão: 10)
+	MaxAttempts int
+	// Middlewares envolvem o enfileiramento de cada publicação, como em publisher.WithMiddleware
+	Middlewares []middleware.Middleware
+}
+
+// Relay publica as mensagens pendentes de um tipo TData gravadas com Insert e as marca como enviadas.
+// As mensagens de um mesmo agregado são publicadas uma a uma, na ordem de inserção, aguardando a
+// confirmação do broker; agregados diferentes são publicados em paralelo. Uma mensagem que esgota
+// MaxAttempts é estacionada (failed_at) e retém as seguintes do seu agregado até ser liberada manualmente.
+type Relay[TData any] struct {
+	db      *sql.DB
+	box     *Outbox
+	options RelayOptions
+	logger  logger.ILogger
+	publish publishFunc[TData]
+}
+
+// publishFunc publica a mensagem e aguarda a confirmação do broker
+type publishFunc[TData any] func(ctx context.Context, topic string, message message.Message[TData], serialization enums.Serialization) (publisher.DeliveryResult, error)
+
+// permanentError é uma falha de publicação que não se resolve com novas tentativas
+type permanentError struct {
+	err error
+}
+
+// markError é a falha ao marcar como enviada uma mensagem já publicada. A mensagem continua pendente
+// e é publicada novamente na próxima rodada, portanto as seguintes do seu agregado devem aguardá-la.
+type markError struct {
+	err error
+}
+
+// entry é uma mensagem pendente lida da tabela de outbox
+type entry struct {
+	id            int64
+	aggregateId   string
+	topic         string
+	serialization enums.Serialization
+	payload       string
+	attempts      int
+}
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+	defaultConcurrency  = 8
+	defaultMaxAttempts  = 10
+)
+
+// ==========================================================================
+// Construtores
+// ==========================================================================
+
+// NewRelay cria o relay das mensagens do tipo TData, publicadas com publisher.PublishAndWait.
+// Apenas um relay deve estar ativo por tabela e tipo: relays simultâneos podem publicar a mesma
+// mensagem mais de uma vez e alterar a ordem de um agregado.
+//
+// Parâmetros:
+//   - ctx: Contexto contendo o container IoC, usado nas publicações
+//   - db: Conexão com o banco de dados da tabela de outbox
+//   - box: Outbox criado com New
+//   - options: Intervalo de consulta, tamanho do lote, paralelismo e middlewares de publicação
+//
+// Retorno:
+//   - *Relay[TData]: Relay pronto para Run
+//   - error: Erro caso as dependências não estejam disponíveis
+func NewRelay[TData any](ctx context.Context, db *sql.DB, box *Outbox, options RelayOptions) (*Relay[TData], error) {
+	container, ok := ctx.Value(constants.IocKey).(ioc.IContainer)
+	if !ok || container == nil {
+		return nil, errors.New("IoC do Kafka não encontrado no contexto")
+	}
+	if db == nil || box == nil {
+		return nil, errors.New("conexão de banco de dados e outbox são obrigatórios para o relay")
+	}
+
+	publish := func(ctx context.Context, topic string, message message.Message[TData], serialization enums.Serialization) (publisher.DeliveryResult, error) {
+		return publisher.PublishAndWait(ctx, topic, message, serialization, publisher.WithMiddleware(options.Middlewares...))
+	}
+	return newRelay(db, box, options, container.GetLogger(), publish), nil
+}
+
+// newRelay cria o relay com a função de publicação informada, aplicando os valores padrão das opções
+func newRelay[TData any](db *sql.DB, box *Outbox, options RelayOptions, log logger.ILogger, publish publishFunc[TData]) *Relay[TData] {
+	if options.PollInterval <= 0 {
+		options.PollInterval = defaultPollInterval
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = defaultConcurrency
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = defaultMaxAttempts
+	}
+
+	return &Relay[TData]{db: db, box: box, options: options, logger: log, publish: publish}
+}
+
+// ==========================================================================
+// Métodos Públicos
+// ==========================================================================
+
+// Run publica as mensagens pendentes até o contexto terminar. Enquanto houver lotes completos sendo
+// publicados a consulta é repetida imediatamente; caso contrário aguarda o PollInterval.
+//
+// Parâmetros:
+//   - ctx: Contexto que encerra o relay; também limita as publicações em andamento
+//
+// Retorno:
+//   - error: Sempre nil; as falhas de cada rodada são registradas em log e repetidas na próxima
+func (r *Relay[TData]) Run(ctx context.Context) error {
+	for {
+		fetched, published, err := r.round(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Error("Error on relay outbox messages", logger.FieldError, err)
+		}
+
+		// Lote completo publicado: ainda pode haver mensagens pendentes
+		if fetched == r.options.BatchSize && published > 0 && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Outbox relay stopped")
+			return nil
+		case <-time.After(r.options.PollInterval):
+		}
+	}
+}
+
+// RelayPending executa uma rodada do relay: lê até BatchSize mensagens pendentes, publica-as e marca
+// como enviadas as confirmadas pelo broker. Quando uma mensagem falha, as seguintes do mesmo agregado
+// aguardam até que ela seja publicada, e as mensagens que já falharam são lidas depois das novas.
+//
+// Parâmetros:
+//   - ctx: Contexto que limita a consulta e as publicações
+//
+// Retorno:
+//   - int: Quantidade de mensagens publicadas
+//   - error: Erro caso as mensagens pendentes não possam ser lidas
+func (r *Relay[TData]) RelayPending(ctx context.Context) (int, error) {
+	_, published, err := r.round(ctx)
+	return published, err
+}
+
+// ==========================================================================
+// Métodos Privados
+// ==========================================================================
+
+// round executa uma rodada do relay e retorna as quantidades de mensagens lidas e publicadas
+func (r *Relay[TData]) round(ctx context.Context) (int, int, error) {
+	entries, err := r.pending(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(entries), r.publishAggregates(ctx, entries), nil
+}
+
+// pending lê as mensagens pendentes do tipo TData que podem ser publicadas, na ordem de inserção de cada agregado
+func (r *Relay[TData]) pending(ctx context.Context) ([]entry, error) {
+	rows, err := r.db.QueryContext(ctx, r.box.pendingQuery, typeName[TData](), r.options.BatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao consultar mensagens pendentes do outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []entry
+	for rows.Next() {
+		var e entry
+		var serialization int
+		if err := rows.Scan(&e.id, &e.aggregateId, &e.topic, &serialization, &e.payload, &e.attempts); err != nil {
+			return nil, fmt.Errorf("falha ao ler mensagem do outbox: %w", err)
+		}
+		e.serialization = enums.Serialization(serialization)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// publishAggregates publica as mensagens agrupadas por agregado: cada agregado em sequência,
+// até Concurrency agregados em paralelo. Retorna a quantidade de mensagens publicadas.
+func (r *Relay[TData]) publishAggregates(ctx context.Context, entries []entry) int {
+	var groups [][]entry
+	index := make(map[string]int)
+	for _, e := range entries {
+		position, ok := index[e.aggregateId]
+		if !ok || e.aggregateId == "" {
+			// Mensagens sem agregado não têm ordem a preservar
+			position = len(groups)
+			index[e.aggregateId] = position
+			groups = append(groups, nil)
+		}
+		groups[position] = append(groups[position], e)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		published int
+	)
+	slots := make(chan struct{}, r.options.Concurrency)
+	for _, group := range groups {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			sent := r.publishAggregate(ctx, group)
+			mu.Lock()
+			published += sent
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return published
+}
+
+// publishAggregate publica em ordem as mensagens de um agregado, parando na primeira falha
+func (r *Relay[TData]) publishAggregate(ctx context.Context, entries []entry) int {
+	for sent, e := range entries {
+		if err := r.publishEntry(ctx, e); err != nil {
+			var mark *markError
+			if errors.As(err, &mark) {
+				// A publicação foi confirmada: a falha da marcação não conta como tentativa
+				r.logger.Error("Error on mark outbox message as sent", "outboxId", e.id, "aggregateId", e.aggregateId, logger.FieldError, err)
+			} else if ctx.Err() == nil {
+				// A interrupção pelo encerramento do relay não conta como tentativa
+				r.fail(ctx, e, err)
+			}
+			return sent
+		}
+	}
+	return len(entries)
+}
+
+// publishEntry publica a mensagem, aguarda a confirmação do broker e a marca como enviada.
+// Se a marcação falhar retorna *markError: a mensagem é publicada novamente na próxima rodada.
+func (r *Relay[TData]) publishEntry(ctx context.Context, e entry) error {
+	var msg message.Message[TData]
+	if err := json.Unmarshal([]byte(e.payload), &msg); err != nil {
+		return &permanentError{fmt.Errorf("falha ao decodificar mensagem do outbox: %w", err)}
+	}
+
+	if _, err := r.publish(ctx, e.topic, msg, e.serialization); err != nil {
+		return err
+	}
+
+	// A publicação já foi confirmada: a marcação deve concluir mesmo com o contexto encerrado
+	if _, err := r.db.ExecContext(context.WithoutCancel(ctx), r.box.sentQuery, time.Now().UnixMilli(), e.id); err != nil {
+		return &markError{fmt.Errorf("falha ao marcar mensagem do outbox como enviada: %w", err)}
+	}
+	return nil
+}
+
+// fail registra a falha de publicação da mensagem. Ao esgotar MaxAttempts, ou quando a falha não pode
+// ser resolvida com novas tentativas, a mensagem é estacionada e deixa de ser lida pelo relay.
+func (r *Relay[TData]) fail(ctx context.Context, e entry, cause error) {
+	var permanent *permanentError
+	var failedAt any
+	if errors.As(cause, &permanent) || e.attempts+1 >= r.options.MaxAttempts {
+		failedAt = time.Now().UnixMilli()
+		r.logger.Error("Outbox message parked", "outboxId", e.id, "aggregateId", e.aggregateId, logger.FieldTopic, e.topic, "attempts", e.attempts+1, logger.FieldError, cause)
+	} else {
+		r.logger.Error("Error on publish outbox message", "outboxId", e.id, "aggregateId", e.aggregateId, logger.FieldTopic, e.topic, "attempts", e.attempts+1, logger.FieldError, cause)
+	}
+
+	if _, err := r.db.ExecContext(context.WithoutCancel(ctx), r.box.failedQuery, cause.Error(), failedAt, e.id); err != nil {
+		r.logger.Error("Error on record outbox failure", "outboxId", e.id, logger.FieldError, err)
+	}
+}
+
+// ==========================================================================
+// Métodos permanentError
+// ==========================================================================
+
+// Error implementa a interface error
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap retorna a causa da falha
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// ==========================================================================
+// Métodos markError
+// ==========================================================================
+
+// Error implementa a interface error
+func (e *markError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap retorna a causa da falha
+func (e *markError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,209 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/logger"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/publisher"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+type pedido struct {
+	Id    string
+	Valor int
+}
+
+const schema = `CREATE TABLE kafka_outbox (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	aggregate_id  VARCHAR(255) NOT NULL,
+	message_type  VARCHAR(255) NOT NULL,
+	topic         VARCHAR(255) NOT NULL,
+	serialization INTEGER NOT NULL,
+	payload       TEXT NOT NULL,
+	created_at    BIGINT NOT NULL,
+	sent_at       BIGINT,
+	attempts      INTEGER NOT NULL DEFAULT 0,
+	last_error    TEXT,
+	failed_at     BIGINT
+)`
+
+// openOutbox cria a tabela de outbox em um banco SQLite em memória
+func openOutbox(t *testing.T) (*sql.DB, *Outbox) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec(schema)
+	require.NoError(t, err)
+
+	box, err := New(Options{})
+	require.NoError(t, err)
+	return db, box
+}
+
+// inserter grava pedidos no outbox, cada um em sua transação, confirmada ou desfeita
+func inserter(t *testing.T, db *sql.DB, box *Outbox) func(aggregateId string, id string, commit bool) {
+	return func(aggregateId string, id string, commit bool) {
+		ctx := context.Background()
+		tx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		msg, err := message.NewForData(uuid.New(), pedido{Id: id, Valor: 10}, nil)
+		require.NoError(t, err)
+		require.NoError(t, Insert(ctx, tx, box, aggregateId, "pedidos", msg, enums.JsonSerialization))
+		if commit {
+			require.NoError(t, tx.Commit())
+		} else {
+			require.NoError(t, tx.Rollback())
+		}
+	}
+}
+
+// Teste do relay com SQLite
+// Apenas mensagens de transações confirmadas são publicadas, e a falha de uma mensagem
+// retém as seguintes do mesmo agregado sem bloquear os demais.
+func TestRelayPreservesAggregateOrder(t *testing.T) {
+	ctx := context.Background()
+	db, box := openOutbox(t)
+	insert := inserter(t, db, box)
+	insert("A", "a1", true)
+	insert("B", "b1", true)
+	insert("A", "a2", true)
+	insert("C", "descartado", false)
+
+	var mu sync.Mutex
+	var published []string
+	failNext := map[string]bool{"a1": true}
+	relay := newRelay(db, box, RelayOptions{Concurrency: 2}, logger.NewNopLogger(),
+		func(ctx context.Context, topic string, msg message.Message[pedido], serialization enums.Serialization) (publisher.DeliveryResult, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if failNext[msg.Data.Id] {
+				delete(failNext, msg.Data.Id)
+				return publisher.DeliveryResult{}, errors.New("broker indisponível")
+			}
+			published = append(published, msg.Data.Id)
+			return publisher.DeliveryResult{Topic: topic}, nil
+		})
+
+	sent, err := relay.RelayPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, []string{"b1"}, published, "A falha de a1 deveria reter a2 sem bloquear o agregado B")
+
+	var attempts int
+	var lastError sql.NullString
+	require.NoError(t, db.QueryRow("SELECT attempts, last_error FROM kafka_outbox WHERE id = 1").Scan(&attempts, &lastError))
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, "broker indisponível", lastError.String)
+
+	sent, err = relay.RelayPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent, "a2 deveria aguardar a publicação de a1")
+
+	sent, err = relay.RelayPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, []string{"b1", "a1", "a2"}, published)
+
+	var pending int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM kafka_outbox WHERE sent_at IS NULL").Scan(&pending))
+	assert.Zero(t, pending)
+}
+
+// Teste de um agregado com falha permanente
+// As mensagens retidas do agregado não ocupam o lote, os demais agregados seguem publicados e a mensagem
+// que esgota as tentativas é estacionada.
+func TestRelayParksPoisonAggregate(t *testing.T) {
+	ctx := context.Background()
+	db, box := openOutbox(t)
+	insert := inserter(t, db, box)
+	for _, id := range []string{"a1", "a2", "a3"} {
+		insert("A", id, true)
+	}
+	for _, id := range []string{"b1", "b2", "b3"} {
+		insert("B", id, true)
+	}
+
+	var published []string
+	relay := newRelay(db, box, RelayOptions{BatchSize: 2, MaxAttempts: 2}, logger.NewNopLogger(),
+		func(ctx context.Context, topic string, msg message.Message[pedido], serialization enums.Serialization) (publisher.DeliveryResult, error) {
+			if msg.Data.Id[0] == 'a' {
+				return publisher.DeliveryResult{}, errors.New("tópico não autorizado")
+			}
+			published = append(published, msg.Data.Id)
+			return publisher.DeliveryResult{Topic: topic}, nil
+		})
+
+	total := 0
+	for range 4 {
+		sent, err := relay.RelayPending(ctx)
+		require.NoError(t, err)
+		total += sent
+	}
+	assert.Equal(t, 3, total)
+	assert.Equal(t, []string{"b1", "b2", "b3"}, published, "O agregado com falha não deveria bloquear os demais")
+
+	var attempts int
+	var failedAt sql.NullInt64
+	require.NoError(t, db.QueryRow("SELECT attempts, failed_at FROM kafka_outbox WHERE id = 1").Scan(&attempts, &failedAt))
+	assert.Equal(t, 2, attempts)
+	assert.True(t, failedAt.Valid, "A mensagem deveria ser estacionada ao esgotar as tentativas")
+
+	var retained int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM kafka_outbox WHERE attempts = 0 AND sent_at IS NULL").Scan(&retained))
+	assert.Equal(t, 2, retained, "As mensagens seguintes do agregado deveriam aguardar sem tentativas")
+
+	sent, err := relay.RelayPending(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, sent, "A mensagem estacionada e as seguintes não deveriam voltar a ser lidas")
+}
+
+// Teste da falha ao marcar uma mensagem como enviada
+// A mensagem publicada que não pôde ser marcada continua pendente, não conta como falha de publicação
+// e retém as seguintes do agregado até ser publicada novamente.
+func TestRelayHoldsAggregateWhenMarkAsSentFails(t *testing.T) {
+	ctx := context.Background()
+	db, box := openOutbox(t)
+	insert := inserter(t, db, box)
+	insert("A", "a1", true)
+	insert("A", "a2", true)
+
+	_, err := db.Exec(`CREATE TRIGGER fail_mark BEFORE UPDATE OF sent_at ON kafka_outbox
+		WHEN NEW.id = 1 BEGIN SELECT RAISE(ABORT, 'banco indisponível'); END`)
+	require.NoError(t, err)
+
+	var published []string
+	relay := newRelay(db, box, RelayOptions{}, logger.NewNopLogger(),
+		func(ctx context.Context, topic string, msg message.Message[pedido], serialization enums.Serialization) (publisher.DeliveryResult, error) {
+			published = append(published, msg.Data.Id)
+			return publisher.DeliveryResult{Topic: topic}, nil
+		})
+
+	sent, err := relay.RelayPending(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+	assert.Equal(t, []string{"a1"}, published, "a2 deveria aguardar a marcação de a1")
+
+	var attempts int
+	var lastError sql.NullString
+	require.NoError(t, db.QueryRow("SELECT attempts, last_error FROM kafka_outbox WHERE id = 1").Scan(&attempts, &lastError))
+	assert.Zero(t, attempts, "A falha da marcação não deveria contar como tentativa de publicação")
+	assert.False(t, lastError.Valid)
+
+	_, err = db.Exec("DROP TRIGGER fail_mark")
+	require.NoError(t, err)
+
+	sent, err = relay.RelayPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Equal(t, []string{"a1", "a1", "a2"}, published, "a1 deveria ser publicada novamente antes de a2")
+}
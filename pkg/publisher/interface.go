@@ -1,6 +1,8 @@
 package publisher
 
 import (
+	"context"
+
 	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
 	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
 )
@@ -13,5 +15,17 @@ import (
 // O parâmetro genérico TData define o tipo de dado que será publicado
 type IPublisher[TData any] interface {
 	// PublishMessage publica uma mensagem no tópico especificado com o formato definido
-	PublishMessage(topic string, message message.Message[TData], serialization enums.Serialization) error
+	PublishMessage(topic string, message message.Message[TData], serialization enums.Serialization, opts ...Option) error
+
+	// PublishAsync publica uma mensagem e retorna um canal com o relatório de entrega
+	PublishAsync(topic string, message message.Message[TData], serialization enums.Serialization, opts ...Option) (<-chan DeliveryResult, error)
+
+	// PublishAndWait publica uma mensagem e aguarda a confirmação do broker
+	PublishAndWait(ctx context.Context, topic string, message message.Message[TData], serialization enums.Serialization, opts ...Option) (DeliveryResult, error)
+
+	// PublishBatch publica um lote de mensagens e retorna o resultado de entrega de cada uma
+	PublishBatch(ctx context.Context, topic string, messages []message.Message[TData], serialization enums.Serialization, opts ...Option) ([]DeliveryResult, error)
+
+	// Close aguarda a entrega das mensagens pendentes e fecha o produtor
+	Close(ctx context.Context) error
 }
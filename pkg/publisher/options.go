@@ -0,0 +1,40 @@
+package publisher
+
+import (
+	engine "github.com/Dieg657/kafka-toolkit-lib/internal/engine/producer"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/middleware"
+)
+
+// ==========================================================================
+// Tipos e Propriedades
+// ==========================================================================
+
+// Option configura um comportamento opcional de uma publicação
+type Option func(options *engine.PublishOptions)
+
+// ==========================================================================
+// Funções Públicas
+// ==========================================================================
+
+// WithMiddleware envolve o enfileiramento da publicação com os middlewares informados, na ordem em que
+// são registrados: o primeiro é o mais externo. Chamadas sucessivas acrescentam middlewares à cadeia.
+// Os middlewares recebem o registro Kafka já serializado, podendo acrescentar cabeçalhos ou recusar
+// a publicação retornando erro. Em PublishBatch a cadeia envolve o enfileiramento de cada mensagem.
+func WithMiddleware(middlewares ...middleware.Middleware) Option {
+	return func(options *engine.PublishOptions) {
+		options.Middlewares = append(options.Middlewares, middlewares...)
+	}
+}
+
+// ==========================================================================
+// Métodos Privados
+// ==========================================================================
+
+// buildOptions aplica as opções informadas sobre a configuração padrão
+func buildOptions(opts []Option) engine.PublishOptions {
+	options := engine.PublishOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
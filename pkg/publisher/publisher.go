@@ -23,6 +23,13 @@ type concretePublisher[TData any] struct {
 	producers sync.Map        // Mapa thread-safe de produtores indexados por tópico
 }
 
+// DeliveryResult é o relatório de entrega de uma mensagem publicada, com partição e offset
+// atribuídos pelo broker ou o erro de entrega
+type DeliveryResult = engine.DeliveryResult
+
+// BatchPublishError indica quantas mensagens de um lote não foram entregues
+type BatchPublishError = engine.BatchPublishError
+
 // Mapa global thread-safe para armazenar instâncias singleton por tipo
 var publisherRegistry sync.Map
 
@@ -72,6 +79,8 @@ func New[TData any](ctx context.Context) *concretePublisher[TData] {
 // ==========================================================================
 
 // PublishMessage publica uma mensagem em um tópico Kafka usando o formato de serialização especificado.
+// Retorna após o enfileiramento, sem aguardar a confirmação do broker; falhas de entrega são registradas
+// em log. Use PublishAndWait quando a confirmação for necessária.
 // Preserva o tipo específico TData durante todo o processo para garantir a serialização correta.
 //
 // Parâmetros:
@@ -80,8 +89,8 @@ func New[TData any](ctx context.Context) *concretePublisher[TData] {
 //   - serialization: Formato de serialização a ser usado (Avro, JSON, etc.)
 //
 // Retorno:
-//   - error: Erro caso ocorra falha na publicação
-func (p *concretePublisher[TData]) PublishMessage(topic string, message message.Message[TData], serialization enums.Serialization) error {
+//   - error: Erro caso ocorra falha na serialização ou no enfileiramento
+func (p *concretePublisher[TData]) PublishMessage(topic string, message message.Message[TData], serialization enums.Serialization, opts ...Option) error {
 	// Obtém ou cria um produtor fortemente tipado para o tópico
 	producer, err := p.getOrCreateProducer(topic)
 	if err != nil {
@@ -90,7 +99,68 @@ func (p *concretePublisher[TData]) PublishMessage(topic string, message message.
 
 	// Usa diretamente o produtor com o tipo TData, sem conversões intermediárias
 	// que poderiam fazer perder informações da interface Avro
-	return producer.Publish(topic, message, serialization)
+	return producer.Publish(topic, message, serialization, buildOptions(opts))
+}
+
+// PublishAsync publica uma mensagem sem aguardar a confirmação do broker.
+// O canal retornado recebe exatamente um DeliveryResult quando a entrega for concluída.
+//
+// Parâmetros:
+//   - topic: Nome do tópico Kafka onde a mensagem será publicada
+//   - message: Mensagem tipada a ser publicada
+//   - serialization: Formato de serialização a ser usado
+//   - opts: Opções da publicação, como WithMiddleware
+//
+// Retorno:
+//   - <-chan DeliveryResult: Canal com o relatório de entrega
+//   - error: Erro caso a serialização ou o enfileiramento falhem
+func (p *concretePublisher[TData]) PublishAsync(topic string, message message.Message[TData], serialization enums.Serialization, opts ...Option) (<-chan DeliveryResult, error) {
+	producer, err := p.getOrCreateProducer(topic)
+	if err != nil {
+		return nil, err
+	}
+	return producer.PublishAsync(topic, message, serialization, buildOptions(opts))
+}
+
+// PublishAndWait publica uma mensagem e aguarda a confirmação do broker ou o cancelamento do contexto.
+//
+// Parâmetros:
+//   - ctx: Contexto que limita a espera pela confirmação
+//   - topic: Nome do tópico Kafka onde a mensagem será publicada
+//   - message: Mensagem tipada a ser publicada
+//   - serialization: Formato de serialização a ser usado
+//   - opts: Opções da publicação, como WithMiddleware
+//
+// Retorno:
+//   - DeliveryResult: Relatório de entrega da mensagem
+//   - error: Erro de publicação, de entrega ou de cancelamento do contexto
+func (p *concretePublisher[TData]) PublishAndWait(ctx context.Context, topic string, message message.Message[TData], serialization enums.Serialization, opts ...Option) (DeliveryResult, error) {
+	producer, err := p.getOrCreateProducer(topic)
+	if err != nil {
+		return DeliveryResult{}, err
+	}
+	return producer.PublishAndWait(ctx, topic, message, serialization, buildOptions(opts))
+}
+
+// PublishBatch publica um lote de mensagens e aguarda o relatório de entrega de todas elas.
+// As mensagens são serializadas em paralelo e enfileiradas na ordem do slice.
+//
+// Parâmetros:
+//   - ctx: Contexto que limita o enfileiramento e a espera pelas confirmações
+//   - topic: Nome do tópico Kafka onde as mensagens serão publicadas
+//   - messages: Mensagens tipadas a serem publicadas
+//   - serialization: Formato de serialização a ser usado
+//   - opts: Opções da publicação, como WithMiddleware
+//
+// Retorno:
+//   - []DeliveryResult: Resultado de entrega de cada mensagem, na mesma ordem de messages
+//   - error: *BatchPublishError se alguma mensagem falhar
+func (p *concretePublisher[TData]) PublishBatch(ctx context.Context, topic string, messages []message.Message[TData], serialization enums.Serialization, opts ...Option) ([]DeliveryResult, error) {
+	producer, err := p.getOrCreateProducer(topic)
+	if err != nil {
+		return nil, err
+	}
+	return producer.PublishBatch(ctx, topic, messages, serialization, buildOptions(opts))
 }
 
 // PublishMessage é uma função estática que centraliza a instanciação e publicação em uma única chamada.
@@ -101,15 +171,91 @@ func (p *concretePublisher[TData]) PublishMessage(topic string, message message.
 //   - topic: Nome do tópico Kafka onde a mensagem será publicada
 //   - message: Mensagem tipada a ser publicada
 //   - serialization: Formato de serialização a ser usado
+//   - opts: Opções da publicação, como WithMiddleware
 //
 // Retorno:
 //   - error: Erro caso ocorra falha na publicação
-func PublishMessage[TData any](ctx context.Context, topic string, message message.Message[TData], serialization enums.Serialization) error {
+func PublishMessage[TData any](ctx context.Context, topic string, message message.Message[TData], serialization enums.Serialization, opts ...Option) error {
 	// Usa a função New para obter ou criar uma instância do publisher
 	publisher := New[TData](ctx)
 
 	// Publica a mensagem usando o publisher obtido
-	return publisher.PublishMessage(topic, message, serialization)
+	return publisher.PublishMessage(topic, message, serialization, opts...)
+}
+
+// PublishAsync é uma função estática que publica a mensagem sem aguardar a confirmação do broker.
+// O canal retornado recebe o relatório de entrega com partição e offset ou o erro.
+//
+// Parâmetros:
+//   - ctx: Contexto contendo o container IoC
+//   - topic: Nome do tópico Kafka onde a mensagem será publicada
+//   - message: Mensagem tipada a ser publicada
+//   - serialization: Formato de serialização a ser usado
+//   - opts: Opções da publicação, como WithMiddleware
+//
+// Retorno:
+//   - <-chan DeliveryResult: Canal com o relatório de entrega
+//   - error: Erro caso a serialização ou o enfileiramento falhem
+func PublishAsync[TData any](ctx context.Context, topic string, message message.Message[TData], serialization enums.Serialization, opts ...Option) (<-chan DeliveryResult, error) {
+	return New[TData](ctx).PublishAsync(topic, message, serialization, opts...)
+}
+
+// PublishAndWait é uma função estática que publica a mensagem e aguarda a confirmação do broker.
+// O contexto também limita o tempo de espera pela confirmação.
+//
+// Parâmetros:
+//   - ctx: Contexto contendo o container IoC
+//   - topic: Nome do tópico Kafka onde a mensagem será publicada
+//   - message: Mensagem tipada a ser publicada
+//   - serialization: Formato de serialização a ser usado
+//   - opts: Opções da publicação, como WithMiddleware
+//
+// Retorno:
+//   - DeliveryResult: Relatório de entrega da mensagem
+//   - error: Erro de publicação, de entrega ou de cancelamento do contexto
+func PublishAndWait[TData any](ctx context.Context, topic string, message message.Message[TData], serialization enums.Serialization, opts ...Option) (DeliveryResult, error) {
+	return New[TData](ctx).PublishAndWait(ctx, topic, message, serialization, opts...)
+}
+
+// PublishBatch é uma função estática que publica um lote de mensagens e aguarda a entrega de todas.
+// O resultado de índice i corresponde à mensagem de índice i; use-o para identificar as falhas.
+//
+// Parâmetros:
+//   - ctx: Contexto contendo o container IoC; também limita a espera pelas confirmações
+//   - topic: Nome do tópico Kafka onde as mensagens serão publicadas
+//   - messages: Mensagens tipadas a serem publicadas
+//   - serialization: Formato de serialização a ser usado
+//   - opts: Opções da publicação, como WithMiddleware
+//
+// Retorno:
+//   - []DeliveryResult: Resultado de entrega de cada mensagem
+//   - error: *BatchPublishError se alguma mensagem falhar
+func PublishBatch[TData any](ctx context.Context, topic string, messages []message.Message[TData], serialization enums.Serialization, opts ...Option) ([]DeliveryResult, error) {
+	return New[TData](ctx).PublishBatch(ctx, topic, messages, serialization, opts...)
+}
+
+// Close aguarda a entrega das mensagens pendentes e fecha o produtor Kafka compartilhado.
+// O produtor é compartilhado por todos os publishers; após o Close nenhum deles pode publicar.
+//
+// Parâmetros:
+//   - ctx: Contexto que limita a espera pelas entregas pendentes
+//
+// Retorno:
+//   - error: Erro caso mensagens fiquem sem entrega
+func (p *concretePublisher[TData]) Close(ctx context.Context) error {
+	return Close(ctx)
+}
+
+// Close aguarda a entrega das mensagens pendentes e fecha os produtores Kafka do container IoC,
+// inclusive o transacional. Deve ser chamado no encerramento da aplicação, após os consumidores.
+//
+// Parâmetros:
+//   - ctx: Contexto contendo o container IoC; limita a espera pelas entregas pendentes
+//
+// Retorno:
+//   - error: Erro caso mensagens fiquem sem entrega antes do fim do contexto
+func Close(ctx context.Context) error {
+	return engine.Close(ctx)
 }
 
 // ==========================================================================
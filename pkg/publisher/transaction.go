@@ -0,0 +1,57 @@
+package publisher
+
+import (
+	"context"
+
+	engine "github.com/Dieg657/kafka-toolkit-lib/internal/engine/producer"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/enums"
+	"github.com/Dieg657/kafka-toolkit-lib/pkg/common/message"
+)
+
+// ==========================================================================
+// Tipos e Propriedades Estáticas
+// ==========================================================================
+
+// Transaction é uma transação do produtor transacional (KAFKA_TRANSACTIONAL_ID).
+// Publicações e offsets enviados na transação são confirmados com Commit ou descartados com Abort.
+// Toda transação iniciada deve ser finalizada, pois o produtor executa uma transação por vez.
+type Transaction = engine.Transaction
+
+// ErrTransactionClosed indica uso de uma transação já confirmada ou abortada
+var ErrTransactionClosed = engine.ErrTransactionClosed
+
+// ErrTransactionalProducerFatal indica que o produtor transacional sofreu um erro fatal e deve ser recriado
+var ErrTransactionalProducerFatal = engine.ErrTransactionalProducerFatal
+
+// ==========================================================================
+// Métodos Públicos
+// ==========================================================================
+
+// BeginTransaction inicia uma transação no produtor transacional.
+// Requer KAFKA_TRANSACTIONAL_ID configurado; aguarda a finalização de uma transação em andamento.
+//
+// Parâmetros:
+//   - ctx: Contexto contendo o container IoC
+//
+// Retorno:
+//   - *Transaction: Transação iniciada
+//   - error: Erro caso o produtor transacional não esteja configurado ou a transação não possa ser iniciada
+func BeginTransaction(ctx context.Context) (*Transaction, error) {
+	return engine.BeginTransaction(ctx)
+}
+
+// PublishInTransaction publica uma mensagem tipada dentro da transação.
+// Mensagens de tipos diferentes podem ser publicadas na mesma transação.
+//
+// Parâmetros:
+//   - tx: Transação em andamento
+//   - topic: Nome do tópico Kafka onde a mensagem será publicada
+//   - message: Mensagem tipada a ser publicada
+//   - serialization: Formato de serialização a ser usado
+//   - opts: Opções da publicação, como WithMiddleware
+//
+// Retorno:
+//   - error: Erro caso a serialização ou o enfileiramento falhem
+func PublishInTransaction[TData any](tx *Transaction, topic string, message message.Message[TData], serialization enums.Serialization, opts ...Option) error {
+	return engine.PublishInTransaction(tx, topic, message, serialization, buildOptions(opts))
+}